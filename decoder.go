@@ -0,0 +1,406 @@
+package goform
+
+import (
+	"fmt"
+	"image"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decoder binds http request data to structs, with options to change how it is
+// done. The zero value is ready to use and binds the same way as Unmarshal.
+//
+// A Decoder is safe for concurrent use once it is configured; its options and
+// registered parsers must not be changed while it is in use.
+type Decoder struct {
+	// UseFirstValue makes a field that isn't a slice or map bind the first of
+	// its values when it receives more than one, instead of returning an error.
+	UseFirstValue bool
+
+	// TrueValues replaces the values a bool field accepts as true. Values are
+	// compared case insensitively. The default is 1, t, true, y, yes and on.
+	TrueValues []string
+
+	// FalseValues replaces the values a bool field accepts as false. Values are
+	// compared case insensitively. The default is 0, f, false, n, no and off.
+	FalseValues []string
+
+	// MatchFieldNames binds exported fields without a form tag using the field
+	// name as the key.
+	MatchFieldNames bool
+
+	// UseJSONTagForForm binds fields without a form tag using the name from
+	// their json tag as the key, so structs shared with json bodies don't need
+	// both tags. Fields tagged `json:"-"` are not bound.
+	UseJSONTagForForm bool
+
+	// SnakeCaseFieldNames makes MatchFieldNames use the snake_case form of the
+	// field name, so UserID is bound from user_id.
+	SnakeCaseFieldNames bool
+
+	// NameFunc, when set, returns the key for exported fields without a form
+	// tag from their field name, like a kebab-case or camelCase form of it.
+	// It replaces MatchFieldNames and SnakeCaseFieldNames, and returning ""
+	// or "-" skips the field.
+	NameFunc func(fieldName string) string
+
+	// JSONOverridesForm decodes a json body after binding the query string, so
+	// values from the body win when both set the same field.
+	JSONOverridesForm bool
+
+	// ErrorOnConflict makes a field set by both the json body and the query
+	// string an error with the ErrConflict code, instead of one overriding
+	// the other.
+	ErrorOnConflict bool
+
+	// MergeSlices appends the values a slice field gets from the query string
+	// to the elements it gets from a json body, instead of one replacing the
+	// other. The query string values come first with JSONOverridesForm.
+	MergeSlices bool
+
+	// DisallowTrailingJSON rejects json bodies with anything but whitespace
+	// after the first value, instead of ignoring it.
+	DisallowTrailingJSON bool
+
+	// DisallowBodyForEmptyStruct rejects requests with a body when v is a
+	// struct without any fields, which would otherwise silently ignore it.
+	DisallowBodyForEmptyStruct bool
+
+	// UseSetters binds unexported fields by calling a method named after the
+	// field with a Set prefix, so a field named total is bound by calling
+	// SetTotal(string) error on a pointer to the struct. Without it, unexported
+	// fields are skipped.
+	UseSetters bool
+
+	// QueryOnlyForBodylessMethods binds GET, HEAD and DELETE requests from the
+	// query string alone, without reading the body or looking at the
+	// Content-Type header.
+	QueryOnlyForBodylessMethods bool
+
+	// Logger, when set, receives a debug event for each field, with key value
+	// pairs describing the field, its key, and where its value came from.
+	Logger func(msg string, kv ...interface{})
+
+	// StrictBase64 rejects files marked with the base64 option unless they are
+	// canonical base64, instead of decoding them leniently.
+	StrictBase64 bool
+
+	// BufferBody reads the whole body into memory before binding, and leaves
+	// r.Body reading those bytes from the start again afterwards, so handlers
+	// and middleware can read the raw body too. MaxContentLength and
+	// MaxTotalUploadBytes still limit how much of the body is read.
+	BufferBody bool
+
+	// MaxContentLength, when positive, rejects requests declaring a larger
+	// Content-Length before anything is parsed.
+	MaxContentLength int64
+
+	// AllowedContentTypes, when not empty, rejects requests whose media type,
+	// like multipart/form-data, isn't one of these. Requests without a
+	// Content-Type have no body to bind, and are still accepted.
+	AllowedContentTypes []string
+
+	// MaxTotalUploadBytes, when positive, limits the combined size of all the
+	// files uploaded in a multipart request. Reading the body stops once it is
+	// more than 1 MB over the limit, leaving room for text fields and part
+	// headers, and the files' exact total is checked once it is parsed.
+	MaxTotalUploadBytes int64
+
+	// GzipContentEncoding gunzips uploaded files whose part has a
+	// Content-Encoding: gzip header, like the gzip option does for a single
+	// field. Gunzipped files without a maxsize tag are limited to
+	// MaxTotalUploadBytes, when it is set, once decoded.
+	GzipContentEncoding bool
+
+	// ReportUnknownFields makes UnmarshalWithResult list the keys in the
+	// request that no field is bound from in its Result, as warnings for
+	// clients sending deprecated or misspelled fields. Binding still succeeds.
+	ReportUnknownFields bool
+
+	// CollectErrors keeps binding after a field fails, returning a MultiError
+	// with every failure instead of just the first.
+	CollectErrors bool
+
+	// UseTimeZoneHeader parses time.Time fields without a tz tag in the
+	// location named by the request's Time-Zone header, like
+	// America/Chicago. Without the header, or with an unknown location, UTC
+	// is used.
+	UseTimeZoneHeader bool
+
+	// MaxDepth limits how deeply nested structs are bound, guarding against
+	// keys like a.a.a.a for recursive types. The default is 32.
+	MaxDepth int
+
+	// AllowedFields, when not nil, lists the only keys that are bound, like
+	// the fields a PATCH request may change. Other fields keep their values,
+	// even when the request, or its json body, has them. The key of a field
+	// is its form key, or its json name for fields without one.
+	AllowedFields []string
+
+	// StrictAllowedFields makes a request that has a value for a field left
+	// out of AllowedFields an error, instead of ignoring the value.
+	StrictAllowedFields bool
+
+	// BlockedFields lists keys that are never bound, like is_admin, so
+	// clients can't set them. Those fields keep their values, even when the
+	// request, or its json body, has them. Fields with the readonly option,
+	// like `form:"id,readonly"`, are treated the same way.
+	BlockedFields []string
+
+	// BindQueryOnBodyError still binds the query string when the body can't
+	// be parsed, returning the body's error as a *BodyError, so a handler can
+	// carry on with what the query string had.
+	BindQueryOnBodyError bool
+
+	// ImageHook, when set, is called with the key and image of each uploaded
+	// image before it is stored, and can return a different image, like a
+	// resized one. An error from it, or a nil image, fails the field.
+	ImageHook func(key string, img image.Image) (image.Image, error)
+
+	parsers map[reflect.Type]func(string) (reflect.Value, error)
+
+	// location is set from the Time-Zone header on a per request copy
+	location *time.Location
+
+	// prefix is prepended to every key by UnmarshalPrefixed, on a per call copy
+	prefix string
+
+	// unknownFields receives the keys no field is bound from, set on a per call
+	// copy by UnmarshalWithResult
+	unknownFields *[]string
+}
+
+// RegisterParser makes d bind fields of type T using fn, which must be a
+// func(string) (T, error). This suits types like time.Weekday or enums that
+// come with their own Parse func. It panics if fn has any other signature.
+func (d *Decoder) RegisterParser(fn interface{}) {
+	fnVal := reflect.ValueOf(fn)
+	fnType := fnVal.Type()
+
+	if fnType.Kind() != reflect.Func ||
+		fnType.NumIn() != 1 || fnType.In(0).Kind() != reflect.String ||
+		fnType.NumOut() != 2 || fnType.Out(1) != reflect.TypeOf((*error)(nil)).Elem() {
+		panic("goform: RegisterParser expects a func(string) (T, error)")
+	}
+
+	d.registerParser(fnType.Out(0), func(value string) (reflect.Value, error) {
+		out := fnVal.Call([]reflect.Value{reflect.ValueOf(value).Convert(fnType.In(0))})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return reflect.Value{}, err
+		}
+
+		return out[0], nil
+	})
+}
+
+// RegisterEnum makes d bind fields of the integer type of zero, like a
+// type Status int with constants, from either one of the names in values or
+// a number. Numbers that don't fit the type are invalid. It panics if zero is
+// not an integer, or if one of values doesn't fit the type.
+//
+//	d.RegisterEnum(Status(0), map[string]int64{"active": int64(StatusActive)})
+func (d *Decoder) RegisterEnum(zero interface{}, values map[string]int64) {
+	t := reflect.TypeOf(zero)
+
+	var unsigned bool
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		unsigned = true
+	default:
+		panic("goform: RegisterEnum expects an integer type")
+	}
+
+	for name, n := range values {
+		var overflows bool
+		if unsigned {
+			overflows = n < 0 || reflect.Zero(t).OverflowUint(uint64(n))
+		} else {
+			overflows = reflect.Zero(t).OverflowInt(n)
+		}
+
+		if overflows {
+			panic(fmt.Sprintf("goform: RegisterEnum value %s overflows %s", name, t.Name()))
+		}
+	}
+
+	d.registerParser(t, func(value string) (reflect.Value, error) {
+		v := reflect.New(t).Elem()
+
+		if n, ok := values[value]; ok {
+			if unsigned {
+				v.SetUint(uint64(n))
+			} else {
+				v.SetInt(n)
+			}
+
+			return v, nil
+		}
+
+		if unsigned {
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil || v.OverflowUint(n) {
+				return reflect.Value{}, fmt.Errorf("goform: invalid %s %q", t.Name(), value)
+			}
+
+			v.SetUint(n)
+		} else {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || v.OverflowInt(n) {
+				return reflect.Value{}, fmt.Errorf("goform: invalid %s %q", t.Name(), value)
+			}
+
+			v.SetInt(n)
+		}
+
+		return v, nil
+	})
+}
+
+func (d *Decoder) registerParser(t reflect.Type, parse func(string) (reflect.Value, error)) {
+	if d.parsers == nil {
+		d.parsers = make(map[reflect.Type]func(string) (reflect.Value, error))
+	}
+
+	d.parsers[t] = parse
+}
+
+// maxDepth returns MaxDepth, or its default when it isn't set.
+func (d *Decoder) maxDepth() int {
+	if d.MaxDepth <= 0 {
+		return defaultMaxDepth
+	}
+
+	return d.MaxDepth
+}
+
+func (d *Decoder) log(msg string, kv ...interface{}) {
+	if d.Logger != nil {
+		d.Logger(msg, kv...)
+	}
+}
+
+// fieldName returns the key used for a field without a form tag, or "" if the
+// field should not be bound.
+func (d *Decoder) fieldName(f reflect.StructField) string {
+	if d.UseJSONTagForForm && f.PkgPath == "" {
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+
+		if name != "" {
+			return name
+		}
+	}
+
+	if d.NameFunc != nil && f.PkgPath == "" {
+		return d.NameFunc(f.Name)
+	}
+
+	if !d.MatchFieldNames || f.PkgPath != "" {
+		return ""
+	}
+
+	if d.SnakeCaseFieldNames {
+		return snakeCase(f.Name)
+	}
+
+	return f.Name
+}
+
+// allowsField reports whether the field with the given key may be bound. The
+// keys of a nested struct's fields are allowed along with the struct, and a
+// nested struct is allowed for any of its fields.
+func (d *Decoder) allowsField(key string) bool {
+	if d.AllowedFields == nil {
+		return true
+	}
+
+	for _, allowed := range d.AllowedFields {
+		if allowed == key || strings.HasPrefix(key, allowed+".") || strings.HasPrefix(allowed, key+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// blocksField reports whether the field with the given key is in
+// BlockedFields.
+func (d *Decoder) blocksField(key string) bool {
+	for _, blocked := range d.BlockedFields {
+		if blocked == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowsContentType reports whether mediaType is in AllowedContentTypes, or
+// AllowedContentTypes is empty.
+func (d *Decoder) allowsContentType(mediaType string) bool {
+	if len(d.AllowedContentTypes) == 0 {
+		return true
+	}
+
+	for _, allowed := range d.AllowedContentTypes {
+		if strings.EqualFold(allowed, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldKey returns the key AllowedFields and BlockedFields know f by.
+func (d *Decoder) fieldKey(f reflect.StructField) string {
+	key, _ := parseTag(f.Tag.Get("form"))
+	if key == "" {
+		key = d.fieldName(f)
+	}
+
+	if key == "" || key == "-" {
+		key = jsonName(f)
+	}
+
+	return key
+}
+
+// timeLocation returns the location for times bound to f: the one named by
+// its tz tag, or the one from the Time-Zone header. It returns nil when there
+// is neither.
+func (d *Decoder) timeLocation(f reflect.StructField) (*time.Location, error) {
+	if tz := f.Tag.Get("tz"); tz != "" {
+		return time.LoadLocation(tz)
+	}
+
+	return d.location, nil
+}
+
+// Clone returns a copy of d, including its registered parsers, that can be
+// changed without affecting d.
+func (d *Decoder) Clone() *Decoder {
+	c := *d
+
+	c.TrueValues = append([]string(nil), d.TrueValues...)
+	c.FalseValues = append([]string(nil), d.FalseValues...)
+
+	if d.AllowedFields != nil {
+		c.AllowedFields = append([]string{}, d.AllowedFields...)
+	}
+
+	c.BlockedFields = append([]string(nil), d.BlockedFields...)
+	c.AllowedContentTypes = append([]string(nil), d.AllowedContentTypes...)
+
+	c.parsers = nil
+	for t, parse := range d.parsers {
+		c.registerParser(t, parse)
+	}
+
+	return &c
+}
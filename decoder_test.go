@@ -0,0 +1,168 @@
+package goform_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rickbassham/goform"
+)
+
+type level int
+
+func TestDecoder_Clone(t *testing.T) {
+	d := &goform.Decoder{
+		UseFirstValue: true,
+		TrueValues:    []string{"yes"},
+	}
+
+	d.RegisterParser(func(value string) (level, error) {
+		return 1, nil
+	})
+
+	c := d.Clone()
+	c.UseFirstValue = false
+	c.TrueValues[0] = "si"
+	c.RegisterParser(func(value string) (level, error) {
+		return 0, errors.New("no levels")
+	})
+
+	type body struct {
+		Enabled bool  `form:"enabled"`
+		Level   level `form:"level"`
+	}
+
+	var b body
+
+	err := d.UnmarshalValues(url.Values{"enabled": {"yes", "no"}, "level": {"high"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Enabled: true,
+		Level:   1,
+	}, b)
+
+	err = c.UnmarshalValues(url.Values{"enabled": {"si"}, "level": {"high"}}, &b)
+	assert.EqualError(t, err, "no levels")
+
+	err = c.UnmarshalValues(url.Values{"enabled": {"si", "no"}}, &b)
+	assert.EqualError(t, err, "goform: arrays not supported yet")
+}
+
+func TestDecoder_Concurrent(t *testing.T) {
+	d := &goform.Decoder{}
+
+	d.RegisterParser(func(value string) (level, error) {
+		return level(len(value)), nil
+	})
+
+	type body struct {
+		ID    int      `form:"id"`
+		Name  string   `form:"name,required"`
+		Tags  []string `form:"tags"`
+		Level level    `form:"level"`
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			var b body
+
+			err := d.UnmarshalValues(url.Values{
+				"id":    {strconv.Itoa(i)},
+				"name":  {"rick"},
+				"tags":  {"a", "b"},
+				"level": {"high"},
+			}, &b)
+			assert.NoError(t, err)
+
+			assert.Equal(t, body{
+				ID:    i,
+				Name:  "rick",
+				Tags:  []string{"a", "b"},
+				Level: 4,
+			}, b)
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func TestDecoder_ConcurrentUnmarshal(t *testing.T) {
+	d := &goform.Decoder{UseTimeZoneHeader: true, ReportUnknownFields: true}
+
+	type body struct {
+		ID      int       `form:"id"`
+		Name    string    `form:"name,required" json:"name"`
+		Created time.Time `form:"created" format:"2006-01-02 15:04"`
+	}
+
+	zones := []string{"UTC", "America/Chicago", "Asia/Tokyo"}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			zone := zones[i%len(zones)]
+
+			loc, err := time.LoadLocation(zone)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			target := "http://test/page?id=" + strconv.Itoa(i) + "&created=2020-01-02+15:04&extra" + strconv.Itoa(i) + "=1"
+
+			var r *http.Request
+			if i%2 == 0 {
+				r, err = http.NewRequest(http.MethodPost, target, strings.NewReader(`{"name": "rick"}`))
+				r.Header.Add("Content-Type", "application/json")
+			} else {
+				r, err = http.NewRequest(http.MethodPost, target, strings.NewReader("name=rick"))
+				r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			r.Header.Add("Time-Zone", zone)
+
+			var b body
+
+			var result goform.Result
+
+			switch i % 3 {
+			case 0:
+				err = d.Unmarshal(r, &b)
+			case 1:
+				err = d.UnmarshalPrefixed(r, &b, "")
+			default:
+				result, err = d.UnmarshalWithResult(r, &b)
+				assert.Equal(t, []string{"extra" + strconv.Itoa(i)}, result.UnknownFields)
+			}
+			assert.NoError(t, err)
+
+			assert.Equal(t, i, b.ID)
+			assert.Equal(t, "rick", b.Name)
+			assert.True(t, time.Date(2020, 1, 2, 15, 4, 0, 0, loc).Equal(b.Created), zone)
+		}(i)
+	}
+
+	wg.Wait()
+}
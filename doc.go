@@ -0,0 +1,182 @@
+// Package goform is meant to make binding http data to structs easy.
+//
+// Unmarshal binds the query string, form values, uploaded files and json body
+// of a request to the fields of a struct, using each field's form tag as its
+// key, like `form:"name"`. Options follow the key, separated by commas, like
+// `form:"name,required"`, and some fields take other tags too. A Decoder
+// changes how binding is done, and its fields document those options.
+//
+// # Keys
+//
+// Fields without a form tag, or tagged `form:"-"` with or without options,
+// are never bound from the query string or form values, but are still set
+// from a json body. Decoder options like MatchFieldNames and UseJSONTagForForm
+// give untagged fields a key. Unexported fields are only bound when the
+// Decoder has UseSetters set. A field with a methods tag, like
+// `methods:"POST,PUT"`, is only bound for requests using one of those methods.
+//
+// A struct field, or pointer to one, has its own fields bound from keys
+// prefixed with its key and a dot, so the Street field of a field tagged
+// `form:"address"` is bound from address.street. A pointer is only allocated
+// when there are keys for it. Nesting deeper than the Decoder's MaxDepth, 32
+// by default, is an error.
+//
+// Keys may use brackets instead of dots, the way html forms usually name
+// nested inputs: user[address][city] is the same key as user.address.city,
+// and trailing empty brackets are dropped, so tags[] is the same as tags.
+// Each segment of a key is one of:
+//
+//	a field of a nested struct, like address in user.address
+//	a map key, like rick in users.rick, for map fields
+//	an index, like 0 in items.0.name, for slices of structs
+//
+// Map keys and values are decoded like any other field, so scores[1]=a binds
+// a map[int]string, and users[rick][age]=39 binds a map[string]User. Slices
+// of structs are ordered by index, with any gaps dropped. Since dots separate
+// segments, map keys can't contain them.
+//
+// A map[string][]string or url.Values field tagged `form:"*"` gets a copy of
+// every value, whatever its key. In a nested struct, or with UnmarshalPrefixed,
+// it only gets the values with keys under that prefix, keyed without it. A
+// *multipart.Form field tagged `form:"*"` gets the parsed form of a multipart
+// request, with all of its values and files, and is left nil for other
+// requests. A url.Values field with the rawquery option, like
+// `form:",rawquery"`, gets a copy of the request's query string, with its keys
+// exactly as sent. It has no key of its own, and is left alone by
+// UnmarshalValues.
+//
+// # Required and read only fields
+//
+// A field with the required option, like `form:"name,required"`, errors when
+// it has no value, unless the json body had a key for it. A field with the
+// readonly option, like `form:"id,readonly"`, is never bound from the
+// request, json body included, and keeps its value.
+//
+// Pointer fields, including pointers to slices like *[]string, are only
+// allocated when there is a value for them, and are left nil otherwise. In
+// general, fields without a value keep the one they had, so a struct loaded
+// from a database can be updated with just the fields a request sends.
+//
+// # Bools
+//
+// Bool fields accept 1, t, true, y, yes and on as true, and 0, f, false, n, no
+// and off as false, ignoring case. A bool field with the checkbox option, like
+// `form:"subscribe,checkbox"`, is set to false when it has no value, since
+// browsers don't submit unchecked checkboxes, and is never missing when
+// required. A bool field with the flag option, like `form:"verbose,flag"`, is
+// set to true by its key alone, like ?verbose, and to false when the key is
+// absent.
+//
+// # Times
+//
+// A time.Time field is parsed with the layout in its format tag, RFC3339 by
+// default, in the location named by its tz tag, or by the request's Time-Zone
+// header when the Decoder has UseTimeZoneHeader set. The format and tz tags
+// apply to strings in json bodies too. The format unix takes seconds since the
+// unix epoch, from json bodies as well as form values. The format
+// datetime-local takes the values of an html datetime-local input, like
+// 2020-01-02T15:04, with or without seconds.
+//
+// A time.Time field with the strict option, like `form:"created,strict"`,
+// must also have exactly the value that formatting the parsed time with its
+// layout gives back.
+//
+// The values of an html time input, like 14:30, can be bound with
+// `format:"15:04"`. A time.Time field then gets that time on January 1 of year
+// 0, the zero date, so only its Hour, Minute and Second are meaningful. A
+// time.Duration field with a format tag gets the time since midnight instead,
+// like 14h30m.
+//
+// # Strings
+//
+// A string field with the email option, like `form:"email,email"`, must be an
+// email address, like rick@example.com. With the utf8 option, like
+// `form:"note,utf8"`, it must be valid UTF-8, and with the sanitizeutf8 option
+// instead, invalid bytes are replaced with the unicode replacement character.
+//
+// A string field with the trim option, like `form:"name,trim"`, has leading
+// and trailing whitespace removed. With the normalizenewlines option, the \r\n
+// and \r line endings browsers send from a textarea become \n, after trimming
+// when both are set.
+//
+// A field with the urldecode option, like `form:"redirect,urldecode"`, has its
+// values percent decoded once more, for clients that encode them twice. A
+// field with the json option, like `form:"address,json"`, is decoded with
+// json.Unmarshal from its value, so a struct or map can be sent as one form
+// value or multipart text part.
+//
+// # Numbers and bytes
+//
+// An int64 or time.Duration field with a unit tag, like `unit:"s"`, takes a
+// plain number counted in that unit. The units are ns, us, ms, s, m and h. A
+// scale tag, like `scale:"2"`, rounds a decimal value to that many places
+// before it is decoded, with halves rounded away from zero.
+//
+// A fixed size byte array, like a [32]byte digest, is decoded from hex, or
+// from base64 with the base64 option, and must be exactly that long. An
+// integer field with an endian tag, like `endian:"big"` or `endian:"little"`,
+// is decoded the same way, as bytes in that order, and there must be exactly
+// as many bytes as the field holds, like 4 for an int32.
+//
+// Fields whose pointer implements encoding.TextUnmarshaler are bound with
+// UnmarshalText. Struct fields whose pointer implements sql.Scanner, like
+// sql.NullString, are bound by passing Scan the value as a string.
+//
+// # Slices and maps
+//
+// Slice fields get an element for each value, decoded using the same tags as
+// the field, and errors name the field and the element that failed. A slice
+// field tagged with the space option, like `form:"scope,space"`, instead gets
+// the words of its values split on whitespace. A slice field with a sep tag,
+// like `form:"ids" sep:"|"`, gets its values split on that separator instead,
+// with empty segments dropped. The csv option, like `form:"dates,csv"`, is
+// short for `sep:","`.
+//
+// A slice field can be given minitems and maxitems tags, like
+// `form:"tags" minitems:"1" maxitems:"10"`, to limit how many elements it
+// has. A field the request leaves out is only an error with the required
+// option. A field with a oneof tag, like `oneof:"red green blue"`, must have
+// one of those space separated values, as must each element of a slice.
+//
+// A map[string]bool field gets a true entry for each of its values, which
+// suits a group of checkboxes sharing a name. A map[string]struct{} field gets
+// the set of its values, with duplicates removed.
+//
+// # Files
+//
+// Uploaded files are bound to []byte, image.Image and io/fs.File fields.
+// Uploads bound to a concrete image type like *image.RGBA are converted to
+// that type. Images can be uploaded as gif, jpeg or png, which this package
+// registers with image.Decode itself. Those decoders end up in every binary
+// importing goform, even ones that never bind an image. Other formats can be
+// added by importing their packages, like golang.org/x/image/webp. A
+// formatfield tag on an image field, like `formatfield:"HeadshotFormat"`,
+// names an exported sibling string field that gets the detected image format,
+// like "png".
+//
+// Files are gunzipped when tagged with the gzip option, or when their part has
+// a Content-Encoding: gzip header and the Decoder has GzipContentEncoding set.
+// With the base64 option too, they are decoded from base64 first. A maxsize
+// tag, like `maxsize:"1048576"`, limits the size in bytes of an uploaded file,
+// both as sent and once decoded, or of a []byte bound from a form value.
+//
+// An io/fs.File field gets the uploaded file as is, without base64 or gzip
+// decoding, and with a Stat giving its name and size. The handler must close
+// it, and can't use it after returning, since the server removes uploads kept
+// on disk once the handler is done.
+//
+// A slice of image.Image or []byte gets every file uploaded under its key,
+// each decoded with the same options. With the base64 option, like
+// `form:"docs,base64"`, every file must be base64, since raw and encoded files
+// can't be told apart reliably. A [][]byte field tagged with the fileprefix
+// option, like `form:"file*,fileprefix"`, receives every uploaded file whose
+// name starts with the tag (without the trailing *), ordered by name.
+//
+// # Errors
+//
+// An error binding a field is returned as a *FieldError, carrying an
+// ErrorCode for the reason. With CollectErrors set on the Decoder, every field
+// is tried and the errors are returned together as a MultiError. A field with
+// an errmsg tag, like `errmsg:"Please provide a valid age"`, has any error
+// binding it replaced by that message.
+package goform
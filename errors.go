@@ -0,0 +1,122 @@
+package goform
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrorCode is a machine readable reason for a field failing to bind, so
+// clients can write their own, localized, messages.
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+const (
+	// ErrRequired means a required field had no value.
+	ErrRequired ErrorCode = "required"
+
+	// ErrParse means a value could not be decoded into the field.
+	ErrParse ErrorCode = "parse"
+
+	// ErrOutOfRange means a value was too big or too small for the field.
+	ErrOutOfRange ErrorCode = "out_of_range"
+
+	// ErrConflict means both the json body and the form values set the
+	// field, when the Decoder has ErrorOnConflict set.
+	ErrConflict ErrorCode = "conflict"
+)
+
+// FieldError is returned when a field fails to bind. It matches its Code with
+// errors.Is, so errors.Is(err, goform.ErrRequired) finds missing fields.
+type FieldError struct {
+	// Field is the key the field is bound from.
+	Field string
+
+	// Code is the reason the field failed to bind.
+	Code ErrorCode
+
+	// Err is the underlying error, or the message from the field's errmsg tag.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the Code of e.
+func (e *FieldError) Is(target error) bool {
+	return target == e.Code
+}
+
+// MultiError holds an error for each field that failed to bind, when the
+// Decoder has CollectErrors set.
+type MultiError []*FieldError
+
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// newFieldError wraps an error binding the field with the given key.
+func newFieldError(key string, err error) *FieldError {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return fieldErr
+	}
+
+	code := ErrParse
+
+	var numErr *strconv.NumError
+	var codedErr *codedError
+
+	switch {
+	case errors.As(err, &codedErr):
+		code = codedErr.code
+	case errors.As(err, &numErr) && numErr.Err == strconv.ErrRange:
+		code = ErrOutOfRange
+	}
+
+	return &FieldError{Field: key, Code: code, Err: err}
+}
+
+// BodyError is returned when the request body couldn't be parsed but, with
+// the BindQueryOnBodyError option, the query string was still bound.
+type BodyError struct {
+	Err error
+}
+
+func (e *BodyError) Error() string {
+	return "goform: invalid body: " + e.Err.Error()
+}
+
+// Unwrap returns the error from parsing the body.
+func (e *BodyError) Unwrap() error {
+	return e.Err
+}
+
+// codedError is an error that knows which ErrorCode it should be reported as.
+type codedError struct {
+	code ErrorCode
+	msg  string
+}
+
+func (e *codedError) Error() string {
+	return e.msg
+}
+
+func outOfRange(format string, args ...interface{}) error {
+	return &codedError{code: ErrOutOfRange, msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,146 @@
+package goform_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rickbassham/goform"
+)
+
+func TestFieldError(t *testing.T) {
+	type body struct {
+		Name string `form:"name,required"`
+		Age  int8   `form:"age"`
+		ID   int    `form:"id"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{}, &b)
+
+	var fieldErr *goform.FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "name", fieldErr.Field)
+	assert.Equal(t, goform.ErrRequired, fieldErr.Code)
+	assert.True(t, errors.Is(err, goform.ErrRequired))
+	assert.EqualError(t, err, "goform: missing required field [name]")
+
+	err = goform.UnmarshalValues(url.Values{"name": {"rick"}, "age": {"300"}}, &b)
+	assert.True(t, errors.Is(err, goform.ErrOutOfRange))
+
+	err = goform.UnmarshalValues(url.Values{"name": {"rick"}, "id": {"abc"}}, &b)
+	assert.True(t, errors.Is(err, goform.ErrParse))
+	assert.False(t, errors.Is(err, goform.ErrRequired))
+}
+
+func TestDecoder_CollectErrors(t *testing.T) {
+	type body struct {
+		Name string `form:"name,required" errmsg:"Please provide your name"`
+		Age  int8   `form:"age"`
+		ID   int    `form:"id"`
+	}
+
+	d := goform.Decoder{CollectErrors: true}
+
+	var b body
+
+	err := d.UnmarshalValues(url.Values{"age": {"300"}, "id": {"1"}}, &b)
+
+	var multiErr goform.MultiError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr, 2)
+
+	assert.Equal(t, "name", multiErr[0].Field)
+	assert.Equal(t, goform.ErrRequired, multiErr[0].Code)
+	assert.EqualError(t, multiErr[0], "Please provide your name")
+
+	assert.Equal(t, "age", multiErr[1].Field)
+	assert.Equal(t, goform.ErrOutOfRange, multiErr[1].Code)
+
+	assert.EqualError(t, err, `Please provide your name; strconv.ParseInt: parsing "300": value out of range`)
+
+	assert.Equal(t, 1, b.ID)
+}
+
+func TestDecoder_BindQueryOnBodyError(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(`{"name": `))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `json:"name"`
+	}
+
+	var b body
+
+	d := goform.Decoder{BindQueryOnBodyError: true}
+
+	err = d.Unmarshal(r, &b)
+	require.Error(t, err)
+
+	var bodyErr *goform.BodyError
+	require.True(t, errors.As(err, &bodyErr))
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	assert.Equal(t, body{ID: 1}, b)
+}
+
+func TestDecoder_BindQueryOnBodyErrorOff(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(`{"name": `))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `json:"name"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.Error(t, err)
+
+	var bodyErr *goform.BodyError
+	assert.False(t, errors.As(err, &bodyErr))
+	assert.Equal(t, body{}, b)
+}
+
+func TestDecoder_ErrorOnConflict(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?name=bob&id=1", strings.NewReader(`{"name": "rick", "age": 39}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name" json:"name"`
+		Age  int    `form:"age" json:"age"`
+	}
+
+	var b body
+
+	d := goform.Decoder{ErrorOnConflict: true}
+
+	err = d.Unmarshal(r, &b)
+	require.Error(t, err)
+
+	var fieldErr *goform.FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "name", fieldErr.Field)
+	assert.Equal(t, goform.ErrConflict, fieldErr.Code)
+	assert.True(t, errors.Is(err, goform.ErrConflict))
+	assert.EqualError(t, err, "goform: field [name] is set by both the json body and the form")
+}
@@ -0,0 +1,53 @@
+package goform
+
+import (
+	"io/fs"
+	"mime/multipart"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+var fsFileType = reflect.TypeOf((*fs.File)(nil)).Elem()
+
+// uploadedFile exposes an uploaded file as an fs.File.
+type uploadedFile struct {
+	multipart.File
+	hdr *multipart.FileHeader
+}
+
+func (f *uploadedFile) Stat() (fs.FileInfo, error) {
+	return fileInfo{hdr: f.hdr}, nil
+}
+
+// fileInfo describes an uploaded file from its part header.
+type fileInfo struct {
+	hdr *multipart.FileHeader
+}
+
+func (fi fileInfo) Name() string {
+	return filepath.Base(fi.hdr.Filename)
+}
+
+func (fi fileInfo) Size() int64 {
+	return fi.hdr.Size
+}
+
+func (fi fileInfo) Mode() fs.FileMode {
+	return 0444
+}
+
+// ModTime is always the zero time, since uploads don't say when the file was
+// last modified.
+func (fi fileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (fi fileInfo) IsDir() bool {
+	return false
+}
+
+// Sys returns the *multipart.FileHeader of the upload.
+func (fi fileInfo) Sys() interface{} {
+	return fi.hdr
+}
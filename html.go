@@ -0,0 +1,129 @@
+package goform
+
+import (
+	"errors"
+	"html/template"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// HTMLForm returns an input element for each field of the struct v points to,
+// named with the key the field is bound from, as a starting point for an html
+// form. Strings get text inputs, numbers get number inputs, bools get
+// checkboxes and []byte, image and fs.File fields get file inputs. Required
+// fields get the required attribute. Nested structs are expanded with their
+// fields' dotted keys, but not within themselves, and read only fields and
+// fields tagged * are left out.
+//
+//	form, err := goform.HTMLForm(&User{})
+func HTMLForm(v interface{}) (template.HTML, error) {
+	return new(Decoder).HTMLForm(v)
+}
+
+// HTMLForm is like the package level HTMLForm, naming fields with the options
+// set on d.
+func (d *Decoder) HTMLForm(v interface{}) (template.HTML, error) {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return "", errors.New("goform: HTMLForm expects a pointer to a struct")
+	}
+
+	var b strings.Builder
+
+	d.writeInputs(&b, val.Elem().Type(), "", map[reflect.Type]bool{})
+
+	return template.HTML(b.String()), nil
+}
+
+// writeInputs writes the input elements for the fields of the struct type t,
+// with their keys prefixed by prefix. Structs already on the path to t, like
+// the Next field of a linked list node, aren't expanded again.
+func (d *Decoder) writeInputs(b *strings.Builder, t reflect.Type, prefix string, path map[reflect.Type]bool) {
+	path[t] = true
+	defer delete(path, t)
+
+	for i, fieldTag := range structTags(t) {
+		f := t.Field(i)
+
+		tag, tagOptions := fieldTag.name, fieldTag.flags
+		if tag == "" {
+			tag = d.fieldName(f)
+		}
+
+		// fields tagged * take every key, so there is no single input for them
+		if tag == "" || tag == "-" || tag == "*" || tagOptions.readonly || tagOptions.rawquery {
+			continue
+		}
+
+		key := prefix + normalizeKey(tag)
+		if tagOptions.fileprefix {
+			key = strings.TrimSuffix(key, "*")
+		}
+
+		if d.blocksField(key) || !d.allowsField(key) {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if !tagOptions.json && d.isNested(ft) {
+			if !path[ft] && len(path) <= d.maxDepth() {
+				d.writeInputs(b, ft, key+".", path)
+			}
+			continue
+		}
+
+		inputType, attrs := inputFor(f, ft, tagOptions)
+
+		b.WriteString(`<input type="` + inputType + `" name="` + template.HTMLEscapeString(key) + `"` + attrs)
+		if tagOptions.required {
+			b.WriteString(" required")
+		}
+		b.WriteString(">\n")
+	}
+}
+
+// inputFor returns the input type for a field of type t, and any attributes
+// that type needs.
+func inputFor(f reflect.StructField, t reflect.Type, tagOptions flags) (string, string) {
+	multiple := ""
+	if t.Kind() == reflect.Slice && t != reflect.TypeOf([]byte{}) {
+		multiple = " multiple"
+	}
+
+	switch {
+	case isFileType(t) || isFileType(reflect.PtrTo(t)):
+		return "file", multiple
+	case t == reflect.TypeOf(time.Time{}):
+		switch f.Tag.Get("format") {
+		case "datetime-local":
+			return "datetime-local", ""
+		case "15:04", "15:04:05":
+			return "time", ""
+		}
+	case t == reflect.TypeOf(time.Duration(0)):
+		if _, ok := f.Tag.Lookup("format"); ok {
+			return "time", ""
+		}
+
+		return "text", ""
+	case tagOptions.email:
+		return "email", ""
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "checkbox", ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "number", ""
+	case reflect.Float32, reflect.Float64:
+		return "number", ` step="any"`
+	}
+
+	return "text", ""
+}
@@ -0,0 +1,85 @@
+package goform_test
+
+import (
+	"image"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rickbassham/goform"
+)
+
+func TestHTMLForm(t *testing.T) {
+	type address struct {
+		Street string `form:"street"`
+	}
+
+	type body struct {
+		Name     string      `form:"name,required"`
+		Email    string      `form:"email,email"`
+		Age      int         `form:"age"`
+		Score    float64     `form:"score"`
+		Agree    bool        `form:"agree"`
+		Avatar   image.Image `form:"avatar"`
+		Files    [][]byte    `form:"files"`
+		Address  address     `form:"address"`
+		ID       string      `form:"id,readonly"`
+		Internal string      `form:"-"`
+	}
+
+	html, err := goform.HTMLForm(&body{})
+	require.NoError(t, err)
+
+	assert.Equal(t, `<input type="text" name="name" required>
+<input type="email" name="email">
+<input type="number" name="age">
+<input type="number" name="score" step="any">
+<input type="checkbox" name="agree">
+<input type="file" name="avatar">
+<input type="file" name="files" multiple>
+<input type="text" name="address.street">
+`, string(html))
+}
+
+func TestHTMLForm_NotStruct(t *testing.T) {
+	var s string
+
+	_, err := goform.HTMLForm(&s)
+	assert.EqualError(t, err, "goform: HTMLForm expects a pointer to a struct")
+}
+
+func TestHTMLForm_SentinelAndPrefix(t *testing.T) {
+	type body struct {
+		Name  string     `form:"name"`
+		All   url.Values `form:"*"`
+		Files [][]byte   `form:"file*,fileprefix"`
+	}
+
+	html, err := goform.HTMLForm(&body{})
+	require.NoError(t, err)
+
+	assert.Equal(t, `<input type="text" name="name">
+<input type="file" name="file" multiple>
+`, string(html))
+}
+
+type htmlNode struct {
+	Name string    `form:"name"`
+	Next *htmlNode `form:"next"`
+}
+
+func TestHTMLForm_Recursive(t *testing.T) {
+	type body struct {
+		Head htmlNode `form:"head"`
+		Tail htmlNode `form:"tail"`
+	}
+
+	html, err := goform.HTMLForm(&body{})
+	require.NoError(t, err)
+
+	assert.Equal(t, `<input type="text" name="head.name">
+<input type="text" name="tail.name">
+`, string(html))
+}
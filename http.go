@@ -0,0 +1,193 @@
+package goform
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// BindJSON binds the request to v like Unmarshal, collecting every field error.
+// If binding fails it writes a 400 Bad Request response with the errors as
+// json, and returns false so the handler can simply return. Clients whose
+// Accept header ranks xml or text/plain above json, by quality value, get the
+// errors in that format instead.
+//
+//	if !goform.BindJSON(w, r, &body) {
+//	    return
+//	}
+func BindJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	return new(Decoder).BindJSON(w, r, v)
+}
+
+// BindJSON is like the package level BindJSON, using the options set on d.
+func (d *Decoder) BindJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	c := *d
+	c.CollectErrors = true
+
+	err := c.Unmarshal(r, v)
+	if err == nil {
+		return true
+	}
+
+	writeErrorBody(w, negotiate(r.Header.Get("Accept")), newErrorBody(err))
+
+	return false
+}
+
+// errorMediaTypes are the media types errors can be written as, each with the
+// names that select it.
+var errorMediaTypes = [][]string{
+	{"application/json"},
+	{"application/xml", "text/xml"},
+	{"text/plain"},
+}
+
+// negotiate returns the media type in an Accept header that errors should be
+// written as, ranking them by their quality values, then by the order they are
+// listed in. Types with a quality of 0 are never picked, and json is the
+// default when nothing is acceptable.
+func negotiate(accept string) string {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return "application/json"
+	}
+
+	best, bestQ, bestPos := "application/json", 0.0, len(ranges)
+
+	for _, names := range errorMediaTypes {
+		q, pos := acceptQuality(ranges, names)
+		if q > bestQ || (q > 0 && q == bestQ && pos < bestPos) {
+			best, bestQ, bestPos = names[0], q, pos
+		}
+	}
+
+	return best
+}
+
+// acceptRange is a media range from an Accept header, like text/* or
+// application/json, with its quality value.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept returns the media ranges in an Accept header. A range without a
+// valid q parameter has a quality of 1.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+
+		q := 1.0
+
+		for _, param := range params[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && parsed >= 0 && parsed <= 1 {
+				q = parsed
+			}
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific range matching any
+// of names, so application/json;q=0 rules out json even alongside */*, and
+// the position of that range, which breaks ties. It returns a quality of 0
+// when no range matches.
+func acceptQuality(ranges []acceptRange, names []string) (float64, int) {
+	q, pos, specificity := 0.0, len(ranges), -1
+
+	for i, r := range ranges {
+		for _, name := range names {
+			s := -1
+
+			switch {
+			case r.mediaType == name:
+				s = 2
+			case strings.HasSuffix(r.mediaType, "/*") && strings.HasPrefix(name, strings.TrimSuffix(r.mediaType, "*")):
+				s = 1
+			case r.mediaType == "*/*":
+				s = 0
+			}
+
+			if s >= 0 && (s > specificity || (s == specificity && r.q > q)) {
+				q, pos, specificity = r.q, i, s
+			}
+		}
+	}
+
+	return q, pos
+}
+
+// writeErrorBody writes body as a 400 Bad Request response of the given media
+// type.
+func writeErrorBody(w http.ResponseWriter, mediaType string, body errorBody) {
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(http.StatusBadRequest)
+
+	switch mediaType {
+	case "application/xml":
+		io.WriteString(w, xml.Header)  // nolint
+		xml.NewEncoder(w).Encode(body) // nolint
+	case "text/plain":
+		for _, item := range body.Errors {
+			fmt.Fprintln(w, item.Message) // nolint
+		}
+	default:
+		json.NewEncoder(w).Encode(body) // nolint
+	}
+}
+
+// errorBody is the response written when binding fails.
+type errorBody struct {
+	XMLName xml.Name    `json:"-" xml:"errors"`
+	Errors  []errorItem `json:"errors" xml:"error"`
+}
+
+type errorItem struct {
+	Field   string    `json:"field,omitempty" xml:"field,attr,omitempty"`
+	Code    ErrorCode `json:"code,omitempty" xml:"code,attr,omitempty"`
+	Message string    `json:"message" xml:",chardata"`
+}
+
+func newErrorBody(err error) errorBody {
+	var multiErr MultiError
+	var fieldErr *FieldError
+
+	switch {
+	case errors.As(err, &multiErr):
+	case errors.As(err, &fieldErr):
+		multiErr = MultiError{fieldErr}
+	default:
+		return errorBody{Errors: []errorItem{{Message: err.Error()}}}
+	}
+
+	body := errorBody{Errors: make([]errorItem, len(multiErr))}
+	for i, fieldErr := range multiErr {
+		body.Errors[i] = errorItem{
+			Field:   fieldErr.Field,
+			Code:    fieldErr.Code,
+			Message: fieldErr.Error(),
+		}
+	}
+
+	return body
+}
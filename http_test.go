@@ -0,0 +1,184 @@
+package goform_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rickbassham/goform"
+)
+
+func TestBindJSON(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `json:"name"`
+	}
+
+	var b body
+
+	w := httptest.NewRecorder()
+
+	ok := goform.BindJSON(w, r, &b)
+	require.True(t, ok)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+	}, b)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestBindJSON_Errors(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?age=old", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		Name string `form:"name,required" errmsg:"Please provide your name"`
+		Age  int    `form:"age"`
+	}
+
+	var b body
+
+	w := httptest.NewRecorder()
+
+	ok := goform.BindJSON(w, r, &b)
+	require.False(t, ok)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"errors": [
+		{"field": "name", "code": "required", "message": "Please provide your name"},
+		{"field": "age", "code": "parse", "message": "strconv.ParseInt: parsing \"old\": invalid syntax"}
+	]}`, w.Body.String())
+}
+
+func TestBindJSON_BodyError(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`{`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		Name string `json:"name"`
+	}
+
+	var b body
+
+	w := httptest.NewRecorder()
+
+	ok := goform.BindJSON(w, r, &b)
+	require.False(t, ok)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"errors": [{"message": "unexpected EOF"}]}`, w.Body.String())
+}
+
+func TestBindJSON_AcceptXML(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?age=old", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Accept", "text/html, application/xml;q=0.9, */*;q=0.8")
+
+	type body struct {
+		Name string `form:"name,required" errmsg:"Please provide your name"`
+		Age  int    `form:"age"`
+	}
+
+	var b body
+
+	w := httptest.NewRecorder()
+
+	ok := goform.BindJSON(w, r, &b)
+	require.False(t, ok)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<errors><error field="name" code="required">Please provide your name</error>`+
+		`<error field="age" code="parse">strconv.ParseInt: parsing &#34;old&#34;: invalid syntax</error></errors>`, w.Body.String())
+}
+
+func TestBindJSON_AcceptText(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?age=old", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Accept", "text/plain")
+
+	type body struct {
+		Name string `form:"name,required" errmsg:"Please provide your name"`
+		Age  int    `form:"age"`
+	}
+
+	var b body
+
+	w := httptest.NewRecorder()
+
+	ok := goform.BindJSON(w, r, &b)
+	require.False(t, ok)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Please provide your name\nstrconv.ParseInt: parsing \"old\": invalid syntax\n", w.Body.String())
+}
+
+func TestBindJSON_AcceptNegotiation(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", "application/json"},
+		{"application/json", "application/json"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8", "application/xml"},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", "application/json"},
+		{"application/json;q=0.1, text/plain", "text/plain"},
+		{"text/plain, application/json", "text/plain"},
+		{"application/json, text/plain", "application/json"},
+		{"text/xml;q=0.5, text/plain;q=0.4", "application/xml"},
+		{"text/*", "application/xml"},
+		{"*/*, application/json;q=0", "application/xml"},
+		{"application/json;q=0, application/xml;q=0, text/plain;q=0", "application/json"},
+		{"image/png", "application/json"},
+		{"TEXT/PLAIN;Q=0.5, application/json;q=0.4", "text/plain"},
+	}
+
+	type body struct {
+		Name string `form:"name,required"`
+	}
+
+	for _, test := range tests {
+		r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(""))
+		require.NoError(t, err)
+		require.NotNil(t, r)
+
+		if test.accept != "" {
+			r.Header.Add("Accept", test.accept)
+		}
+
+		var b body
+
+		w := httptest.NewRecorder()
+
+		ok := goform.BindJSON(w, r, &b)
+		require.False(t, ok)
+
+		assert.Equal(t, test.expected, w.Header().Get("Content-Type"), test.accept)
+	}
+}
@@ -0,0 +1,74 @@
+package goform
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"reflect"
+
+	// register the standard formats with image.Decode, so image fields work
+	// without blank imports in every program
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+var imageType = reflect.TypeOf((*image.Image)(nil)).Elem()
+
+// setImage stores img in valf, converting it when valf is a concrete image
+// type that img is not already.
+func setImage(valf reflect.Value, img image.Image) error {
+	imgVal := reflect.ValueOf(img)
+	if imgVal.Type().AssignableTo(valf.Type()) {
+		valf.Set(imgVal)
+		return nil
+	}
+
+	dst := newImage(valf.Type(), img.Bounds())
+	if dst == nil {
+		return fmt.Errorf("goform: cannot convert %s to %s", imgVal.Type(), valf.Type())
+	}
+
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	valf.Set(reflect.ValueOf(dst))
+
+	return nil
+}
+
+// isNilImage reports whether img is nil, or a nil pointer to an image type.
+func isNilImage(img image.Image) bool {
+	if img == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(img)
+
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// newImage allocates an image of type t, or returns nil if t is not one of
+// the image types from the standard library.
+func newImage(t reflect.Type, r image.Rectangle) draw.Image {
+	switch t {
+	case reflect.TypeOf(&image.RGBA{}):
+		return image.NewRGBA(r)
+	case reflect.TypeOf(&image.RGBA64{}):
+		return image.NewRGBA64(r)
+	case reflect.TypeOf(&image.NRGBA{}):
+		return image.NewNRGBA(r)
+	case reflect.TypeOf(&image.NRGBA64{}):
+		return image.NewNRGBA64(r)
+	case reflect.TypeOf(&image.Gray{}):
+		return image.NewGray(r)
+	case reflect.TypeOf(&image.Gray16{}):
+		return image.NewGray16(r)
+	case reflect.TypeOf(&image.Alpha{}):
+		return image.NewAlpha(r)
+	case reflect.TypeOf(&image.Alpha16{}):
+		return image.NewAlpha16(r)
+	case reflect.TypeOf(&image.CMYK{}):
+		return image.NewCMYK(r)
+	}
+
+	return nil
+}
@@ -0,0 +1,328 @@
+package goform
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UnmarshalEach decodes a request body holding a json array one element at a
+// time, without reading the whole array into memory. Each element is decoded
+// into a new T, and passed to fn, which must be a func(*T) error where T is a
+// struct. Read only, blocked and disallowed fields are left alone, as they are
+// by Unmarshal. The first error from fn stops decoding and is returned.
+// UnmarshalEach panics if fn has any other signature.
+//
+//	err := goform.UnmarshalEach(r, func(u *User) error {
+//		return store.Save(u)
+//	})
+func UnmarshalEach(r *http.Request, fn interface{}) error {
+	return new(Decoder).UnmarshalEach(r, fn)
+}
+
+// UnmarshalEach decodes each element of a json array like the package level
+// UnmarshalEach, using the options set on d.
+func (d *Decoder) UnmarshalEach(r *http.Request, fn interface{}) error {
+	fnVal := reflect.ValueOf(fn)
+	fnType := fnVal.Type()
+
+	if fnType.Kind() != reflect.Func ||
+		fnType.NumIn() != 1 || fnType.In(0).Kind() != reflect.Ptr || fnType.In(0).Elem().Kind() != reflect.Struct ||
+		fnType.NumOut() != 1 || fnType.Out(0) != reflect.TypeOf((*error)(nil)).Elem() {
+		panic("goform: UnmarshalEach expects a func(*T) error")
+	}
+
+	body := r.Body
+	if body == nil {
+		body = http.NoBody
+	}
+
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return errors.New("goform: expected a json array")
+	}
+
+	for dec.More() {
+		var raw json.RawMessage
+
+		err = dec.Decode(&raw)
+		if err != nil {
+			return err
+		}
+
+		v := reflect.New(fnType.In(0).Elem())
+		if def, ok := v.Interface().(Defaulter); ok {
+			def.Defaults()
+		}
+
+		// read only, blocked and disallowed fields are kept, as in Unmarshal
+		err = d.decodeBody(source{json: jsonKeys(raw)}, raw, v.Interface())
+		if err != nil {
+			return err
+		}
+
+		out := fnVal.Call([]reflect.Value{v})
+		if err, _ := out[0].Interface().(error); err != nil {
+			return err
+		}
+	}
+
+	// the closing bracket
+	_, err = dec.Token()
+	if err != nil {
+		return err
+	}
+
+	return d.checkTrailingJSON(dec)
+}
+
+// readJSON reads a single json value from body, so it can be decoded later.
+func (d *Decoder) readJSON(body io.Reader) (json.RawMessage, error) {
+	dec := json.NewDecoder(body)
+
+	var raw json.RawMessage
+
+	err := dec.Decode(&raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.checkTrailingJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// jsonKeys returns the lower cased keys of a json object, so fields set by
+// the json body can be found the way encoding/json matches them.
+func jsonKeys(raw json.RawMessage) map[string]bool {
+	var obj map[string]json.RawMessage
+
+	// anything but an object sets no fields
+	json.Unmarshal(raw, &obj) // nolint
+
+	keys := map[string]bool{}
+	for key := range obj {
+		keys[strings.ToLower(key)] = true
+	}
+
+	return keys
+}
+
+// jsonName returns the name encoding/json uses for f.
+func jsonName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" {
+		name = f.Name
+	}
+
+	return name
+}
+
+// decodeBody decodes a json body into v, keeping the fields that are read
+// only, blocked, or left out of AllowedFields as they were.
+func (d *Decoder) decodeBody(src source, raw json.RawMessage, v interface{}) error {
+	val := reflect.Indirect(reflect.ValueOf(v))
+	t := val.Type()
+
+	kept := map[int]reflect.Value{}
+
+	for i, fieldTag := range structTags(t) {
+		f := t.Field(i)
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		key := d.fieldKey(f)
+		tagOptions := fieldTag.flags
+
+		switch {
+		case tagOptions.readonly || d.blocksField(key):
+		case !d.allowsField(key):
+			if d.StrictAllowedFields && src.inJSON(f) {
+				return fmt.Errorf("goform: field [%s] is not allowed", key)
+			}
+		default:
+			continue
+		}
+
+		old := reflect.New(f.Type).Elem()
+		old.Set(val.Field(i))
+		kept[i] = old
+	}
+
+	merged := d.mergedSlices(src, val)
+
+	err := d.decodeJSON(raw, v)
+
+	for i, old := range kept {
+		val.Field(i).Set(old)
+	}
+
+	for i, old := range merged {
+		val.Field(i).Set(reflect.AppendSlice(old, val.Field(i)))
+	}
+
+	return err
+}
+
+// mergedSlices returns the slice fields of val that MergeSlices appends the
+// json body's elements to, which are those already bound from form values
+// when the body is decoded last.
+func (d *Decoder) mergedSlices(src source, val reflect.Value) map[int]reflect.Value {
+	merged := map[int]reflect.Value{}
+
+	if !d.MergeSlices || !d.JSONOverridesForm {
+		return merged
+	}
+
+	t := val.Type()
+
+	for i, fieldTag := range structTags(t) {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Type.Kind() != reflect.Slice || !src.inJSON(f) {
+			continue
+		}
+
+		tag := fieldTag.name
+		if tag == "" {
+			tag = d.fieldName(f)
+		}
+
+		// copied, since encoding/json reuses the slice's backing array
+		if len(src.values[tag]) > 0 {
+			old := val.Field(i)
+			merged[i] = reflect.MakeSlice(old.Type(), old.Len(), old.Len())
+			reflect.Copy(merged[i], old)
+		}
+	}
+
+	return merged
+}
+
+// decodeJSON decodes a json body into v. Values for time.Time fields that
+// encoding/json can't parse, like unix timestamps or strings in the layout of
+// a format tag, are rewritten first.
+func (d *Decoder) decodeJSON(raw json.RawMessage, v interface{}) error {
+	t := reflect.TypeOf(v).Elem()
+
+	fields := jsonTimeFields(t)
+	if len(fields) == 0 {
+		return json.Unmarshal(raw, v)
+	}
+
+	var obj map[string]json.RawMessage
+
+	err := json.Unmarshal(raw, &obj)
+	if err != nil {
+		return err
+	}
+
+	for key, raw := range obj {
+		for name, f := range fields {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+
+			tagOptions := structTags(t)[f.Index[0]].flags
+			tagOptions.key = name
+
+			obj[key], err = d.rewriteJSONTime(f, tagOptions, raw)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// checkTrailingJSON makes sure nothing but whitespace follows the json value
+// dec has decoded, when DisallowTrailingJSON is set.
+func (d *Decoder) checkTrailingJSON(dec *json.Decoder) error {
+	if !d.DisallowTrailingJSON {
+		return nil
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("goform: unexpected data after json body")
+	}
+
+	return nil
+}
+
+// jsonTimeFields returns the time.Time fields of t that need rewriting, keyed
+// by their json name.
+func jsonTimeFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if ft != reflect.TypeOf(time.Time{}) || f.Tag.Get("format") == "" {
+			continue
+		}
+
+		name := jsonName(f)
+		if name == "-" {
+			continue
+		}
+
+		fields[name] = f
+	}
+
+	return fields
+}
+
+// rewriteJSONTime turns a unix timestamp, or a string parsed with the format
+// and tz tags of f, into an RFC 3339 string that time.Time can unmarshal.
+// Anything else is left for encoding/json to handle.
+func (d *Decoder) rewriteJSONTime(f reflect.StructField, tagOptions flags, raw json.RawMessage) (json.RawMessage, error) {
+	var formValue string
+
+	if f.Tag.Get("format") == "unix" {
+		if _, err := strconv.ParseInt(string(raw), 10, 64); err != nil {
+			return raw, nil
+		}
+
+		formValue = string(raw)
+	} else if err := json.Unmarshal(raw, &formValue); err != nil {
+		return raw, nil
+	}
+
+	var t time.Time
+
+	err := d.decodeStruct(reflect.ValueOf(&t).Elem(), f, tagOptions, formValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(t.Format(time.RFC3339Nano))
+}
@@ -0,0 +1,266 @@
+package goform_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rickbassham/goform"
+)
+
+func TestUnmarshal_JSONUnixTime(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`{"ts": 1700000000, "Updated": "2020-01-02T15:04:05Z", "seen": 1600000000, "name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		TS      time.Time  `json:"ts" format:"unix"`
+		Updated time.Time  `format:"unix"`
+		Seen    *time.Time `json:"seen" format:"unix"`
+		Name    string     `json:"name"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	seen := time.Unix(1600000000, 0).UTC()
+
+	assert.Equal(t, body{
+		TS:      time.Unix(1700000000, 0).UTC(),
+		Updated: time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC),
+		Seen:    &seen,
+		Name:    "rick",
+	}, b)
+}
+
+func TestUnmarshal_UnixTime(t *testing.T) {
+	type body struct {
+		TS time.Time `form:"ts" format:"unix"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"ts": {"1700000000"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		TS: time.Unix(1700000000, 0).UTC(),
+	}, b)
+}
+
+func TestDecoder_DisallowTrailingJSON(t *testing.T) {
+	newRequest := func(body string) *http.Request {
+		r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(body))
+		require.NoError(t, err)
+		require.NotNil(t, r)
+
+		r.Header.Add("Content-Type", "application/json")
+
+		return r
+	}
+
+	type body struct {
+		Name string    `json:"name"`
+		TS   time.Time `json:"ts" format:"unix"`
+	}
+
+	type plainBody struct {
+		Name string `json:"name"`
+	}
+
+	var b body
+	var p plainBody
+
+	err := goform.Unmarshal(newRequest(`{"name": "rick"} {"name": "bob"}`), &p)
+	require.NoError(t, err)
+	assert.Equal(t, "rick", p.Name)
+
+	d := goform.Decoder{DisallowTrailingJSON: true}
+
+	err = d.Unmarshal(newRequest(`{"name": "rick"}`+"\n\t "), &p)
+	require.NoError(t, err)
+
+	err = d.Unmarshal(newRequest(`{"name": "rick"} {"name": "bob"}`), &p)
+	assert.EqualError(t, err, "goform: unexpected data after json body")
+
+	err = d.Unmarshal(newRequest(`{"name": "rick"} garbage`), &p)
+	assert.EqualError(t, err, "goform: unexpected data after json body")
+
+	err = d.Unmarshal(newRequest(`{"name": "rick", "ts": 1} ]`), &b)
+	assert.EqualError(t, err, "goform: unexpected data after json body")
+}
+
+func TestUnmarshalEach(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`[{"name": "rick", "ts": 1600000000}, {"name": "bob"}]`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		Name string    `json:"name"`
+		TS   time.Time `json:"ts" format:"unix"`
+	}
+
+	var got []body
+
+	err = goform.UnmarshalEach(r, func(b *body) error {
+		got = append(got, *b)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []body{
+		{Name: "rick", TS: time.Unix(1600000000, 0).UTC()},
+		{Name: "bob"},
+	}, got)
+}
+
+func TestUnmarshalEach_CallbackError(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`[{"name": "rick"}, {"name": "bob"}, {"name": "jim"}]`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	type body struct {
+		Name string `json:"name"`
+	}
+
+	errStop := errors.New("stop")
+
+	var names []string
+
+	err = goform.UnmarshalEach(r, func(b *body) error {
+		names = append(names, b.Name)
+		if b.Name == "bob" {
+			return errStop
+		}
+
+		return nil
+	})
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, []string{"rick", "bob"}, names)
+}
+
+func TestUnmarshalEach_NotArray(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	type body struct {
+		Name string `json:"name"`
+	}
+
+	err = goform.UnmarshalEach(r, func(b *body) error { return nil })
+	assert.EqualError(t, err, "goform: expected a json array")
+}
+
+func TestUnmarshalEach_ReadOnlyAndBlocked(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`[{"id": 5, "admin": true, "name": "rick"}]`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	type body struct {
+		ID    int    `json:"id" form:"id,readonly"`
+		Admin bool   `json:"admin" form:"admin"`
+		Name  string `json:"name" form:"name"`
+	}
+
+	d := goform.Decoder{BlockedFields: []string{"admin"}}
+
+	var got []body
+
+	err = d.UnmarshalEach(r, func(b *body) error {
+		got = append(got, *b)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []body{{Name: "rick"}}, got)
+
+	r, err = http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`[{"admin": true}]`))
+	require.NoError(t, err)
+
+	d = goform.Decoder{AllowedFields: []string{"name"}, StrictAllowedFields: true}
+
+	err = d.UnmarshalEach(r, func(b *body) error { return nil })
+	assert.EqualError(t, err, "goform: field [admin] is not allowed")
+}
+
+func TestUnmarshalEach_NilBody(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", nil)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	type body struct {
+		Name string `json:"name"`
+	}
+
+	err = goform.UnmarshalEach(r, func(b *body) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestUnmarshalEach_BadCallback(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`[]`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	assert.PanicsWithValue(t, "goform: UnmarshalEach expects a func(*T) error", func() {
+		goform.UnmarshalEach(r, func(s string) {}) // nolint
+	})
+}
+
+func TestUnmarshal_JSONTimeFormat(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`{"day": "2020-01-02", "at": "2020-01-02 15:04", "seen": "2020-01-02", "created": "2020-01-02T15:04:05Z"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		Day     time.Time  `json:"day" format:"2006-01-02"`
+		At      time.Time  `json:"at" format:"2006-01-02 15:04" tz:"America/Chicago"`
+		Seen    *time.Time `json:"seen" format:"2006-01-02"`
+		Created time.Time  `json:"created"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	assert.True(t, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Equal(b.Day))
+	assert.True(t, time.Date(2020, 1, 2, 15, 4, 0, 0, chicago).Equal(b.At))
+	require.NotNil(t, b.Seen)
+	assert.True(t, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Equal(*b.Seen))
+	assert.True(t, time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC).Equal(b.Created))
+}
+
+func TestUnmarshal_JSONTimeFormatInvalid(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`{"day": "01/02/2020"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		Day time.Time `json:"day" format:"2006-01-02"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	assert.EqualError(t, err, `parsing time "01/02/2020" as "2006-01-02": cannot parse "01/02/2020" as "2006"`)
+}
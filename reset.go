@@ -0,0 +1,52 @@
+package goform
+
+import "reflect"
+
+// Reset zeroes every field of the struct v points to that Unmarshal would
+// bind, so a struct taken from a sync.Pool doesn't carry values over from the
+// last request. Fields without a key, or tagged `form:"-"`, are left alone.
+// Reset panics if v is not a pointer to a struct.
+//
+//	b := pool.Get().(*Body)
+//	defer pool.Put(b)
+//	goform.Reset(b)
+func Reset(v interface{}) {
+	new(Decoder).Reset(v)
+}
+
+// Reset is like the package level Reset, finding the fields' keys with the
+// options set on d.
+func (d *Decoder) Reset(v interface{}) {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		panic("goform: Reset expects a pointer to a struct")
+	}
+
+	d.resetStruct(val.Elem())
+}
+
+func (d *Decoder) resetStruct(val reflect.Value) {
+	t := val.Type()
+
+	for i, fieldTag := range structTags(t) {
+		f := t.Field(i)
+		valf := val.Field(i)
+
+		tag := fieldTag.name
+		if tag == "" && !fieldTag.flags.rawquery {
+			tag = d.fieldName(f)
+		}
+
+		if (tag == "" && !fieldTag.flags.rawquery) || tag == "-" || !valf.CanSet() {
+			continue
+		}
+
+		// nested structs may hold fields that aren't bound
+		if f.Type.Kind() == reflect.Struct && !fieldTag.flags.json && d.isNested(f.Type) {
+			d.resetStruct(valf)
+			continue
+		}
+
+		valf.Set(reflect.Zero(f.Type))
+	}
+}
@@ -0,0 +1,55 @@
+package goform_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rickbassham/goform"
+)
+
+func TestReset(t *testing.T) {
+	type address struct {
+		Street string `form:"street"`
+		Note   string
+	}
+
+	type body struct {
+		ID       int        `form:"id"`
+		Tags     []string   `form:"tags"`
+		Address  address    `form:"address"`
+		Query    url.Values `form:",rawquery"`
+		Skipped  string     `form:"-"`
+		Untagged string
+	}
+
+	b := body{
+		ID:       1,
+		Tags:     []string{"a"},
+		Address:  address{Street: "main", Note: "kept"},
+		Query:    url.Values{"id": {"1"}},
+		Skipped:  "kept",
+		Untagged: "kept",
+	}
+
+	goform.Reset(&b)
+
+	assert.Equal(t, body{
+		Address:  address{Note: "kept"},
+		Skipped:  "kept",
+		Untagged: "kept",
+	}, b)
+
+	d := goform.Decoder{MatchFieldNames: true}
+
+	b.Untagged = "reset"
+	d.Reset(&b)
+
+	assert.Empty(t, b.Untagged)
+
+	require.PanicsWithValue(t, "goform: Reset expects a pointer to a struct", func() {
+		goform.Reset(b)
+	})
+}
@@ -0,0 +1,174 @@
+package goform
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Result describes what binding a request took, for metering or quotas.
+type Result struct {
+	// BytesRead is how much of the request body was read, uploaded files
+	// included.
+	BytesRead int64
+
+	// UnknownFields lists, sorted, the keys in the query string, form values,
+	// uploaded files or json body that no field is bound from, with json keys
+	// lower cased. It is only filled in by a Decoder with ReportUnknownFields
+	// set.
+	UnknownFields []string
+}
+
+// UnmarshalWithResult binds the request to v like Unmarshal, and also returns
+// a Result describing it.
+func UnmarshalWithResult(r *http.Request, v interface{}) (Result, error) {
+	return new(Decoder).UnmarshalWithResult(r, v)
+}
+
+// UnmarshalWithResult binds the request to v like the package level
+// UnmarshalWithResult, using the options set on d.
+func (d *Decoder) UnmarshalWithResult(r *http.Request, v interface{}) (Result, error) {
+	var result Result
+
+	if d.ReportUnknownFields {
+		c := *d
+		c.unknownFields = &result.UnknownFields
+		d = &c
+	}
+
+	if r.Body == nil {
+		return result, d.Unmarshal(r, v)
+	}
+
+	body := &countingReader{ReadCloser: r.Body}
+
+	r.Body = body
+	// BufferBody leaves a fresh reader on r.Body, which must be kept
+	defer func() {
+		if r.Body == body {
+			r.Body = body.ReadCloser
+		}
+	}()
+
+	err := d.Unmarshal(r, v)
+
+	result.BytesRead = body.n
+
+	return result, err
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// knownKeys records the keys fields are bound from while binding, so the
+// rest of the request's keys can be reported as unknown.
+type knownKeys struct {
+	keys     map[string]bool
+	prefixes []string
+}
+
+// add records the key of a field of type t. Maps and slices of structs are
+// also bound from keys under their own.
+func (k *knownKeys) add(key string, t reflect.Type, tagOptions flags) {
+	if k == nil {
+		return
+	}
+
+	switch {
+	case tagOptions.fileprefix:
+		k.prefixes = append(k.prefixes, strings.TrimSuffix(key, "*"))
+	default:
+		k.keys[key] = true
+
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() == reflect.Map || t.Kind() == reflect.Slice {
+			k.prefixes = append(k.prefixes, key+".")
+		}
+	}
+}
+
+// addPrefix records that a field tagged with the * sentinel takes every key
+// starting with prefix.
+func (k *knownKeys) addPrefix(prefix string) {
+	if k == nil {
+		return
+	}
+
+	k.prefixes = append(k.prefixes, prefix)
+}
+
+// known reports whether key is one a field is bound from.
+func (k *knownKeys) known(key string) bool {
+	if k.keys[key] {
+		return true
+	}
+
+	for _, prefix := range k.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unknown returns the sorted keys of src that no field is bound from. Keys
+// of a json body are matched against the json names of the fields of t.
+func (k *knownKeys) unknown(src source, t reflect.Type) []string {
+	seen := map[string]bool{}
+
+	for key := range src.values {
+		if !k.known(key) {
+			seen[key] = true
+		}
+	}
+
+	for key := range src.files {
+		if !k.known(key) {
+			seen[key] = true
+		}
+	}
+
+	if len(src.json) > 0 {
+		names := map[string]bool{}
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.PkgPath == "" {
+				names[strings.ToLower(jsonName(f))] = true
+			}
+		}
+
+		for key := range src.json {
+			if !names[key] {
+				seen[key] = true
+			}
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil
+	}
+
+	unknown := make([]string, 0, len(seen))
+	for key := range seen {
+		unknown = append(unknown, key)
+	}
+
+	sort.Strings(unknown)
+
+	return unknown
+}
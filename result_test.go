@@ -0,0 +1,174 @@
+package goform_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rickbassham/goform"
+)
+
+func TestUnmarshalWithResult(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormField(w, "name", "rick")
+	writeFormFile(w, "data", strings.NewReader("ABCD"))
+
+	w.Close() // nolint
+
+	size := int64(buf.Len())
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+		Data []byte `form:"data"`
+	}
+
+	var b body
+
+	result, err := goform.UnmarshalWithResult(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, goform.Result{BytesRead: size}, result)
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+		Data: []byte("ABCD"),
+	}, b)
+}
+
+func TestUnmarshalWithResult_JSON(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		Name string `json:"name"`
+	}
+
+	var b body
+
+	result, err := goform.UnmarshalWithResult(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, goform.Result{BytesRead: 16}, result)
+	assert.Equal(t, body{Name: "rick"}, b)
+}
+
+func TestUnmarshalWithResult_BufferBody(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1&debug=1", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `json:"name"`
+	}
+
+	var b body
+
+	d := goform.Decoder{BufferBody: true, ReportUnknownFields: true}
+
+	result, err := d.UnmarshalWithResult(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, goform.Result{BytesRead: 16, UnknownFields: []string{"debug"}}, result)
+	assert.Equal(t, body{ID: 1, Name: "rick"}, b)
+
+	raw, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name": "rick"}`, string(raw))
+}
+
+func TestDecoder_ReportUnknownFields(t *testing.T) {
+	data := url.Values{}
+	data.Set("name", "rick")
+	data.Set("nmae", "typo")
+	data.Set("address[city]", "Austin")
+	data.Set("address[zip]", "78701")
+	data.Set("tags[]", "a")
+	data.Set("labels.color", "red")
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1&debug=1", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type address struct {
+		City string `form:"city"`
+	}
+
+	type body struct {
+		ID      int               `form:"id"`
+		Name    string            `form:"name"`
+		Address address           `form:"address"`
+		Tags    []string          `form:"tags"`
+		Labels  map[string]string `form:"labels"`
+	}
+
+	var b body
+
+	d := goform.Decoder{ReportUnknownFields: true}
+
+	result, err := d.UnmarshalWithResult(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"address.zip", "debug", "nmae"}, result.UnknownFields)
+	assert.Equal(t, body{
+		ID:      1,
+		Name:    "rick",
+		Address: address{City: "Austin"},
+		Tags:    []string{"a"},
+		Labels:  map[string]string{"color": "red"},
+	}, b)
+
+	// off by default
+	r, err = http.NewRequest(http.MethodGet, "http://test/page?id=1&debug=1", strings.NewReader(""))
+	require.NoError(t, err)
+
+	result, err = goform.UnmarshalWithResult(r, &b)
+	require.NoError(t, err)
+	assert.Nil(t, result.UnknownFields)
+}
+
+func TestDecoder_ReportUnknownFieldsJSON(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(`{"name": "rick", "Legacy": true}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `json:"name"`
+	}
+
+	var b body
+
+	d := goform.Decoder{ReportUnknownFields: true}
+
+	result, err := d.UnmarshalWithResult(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"legacy"}, result.UnknownFields)
+	assert.Equal(t, body{ID: 1, Name: "rick"}, b)
+}
@@ -5,11 +5,66 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 )
 
+// tagCache holds the parsed form tags of each struct type bound so far, so
+// they aren't parsed again on every request. A single Decoder serves many
+// goroutines, so it must be safe for concurrent use.
+var tagCache sync.Map // map[reflect.Type][]fieldTag
+
+// fieldTag is the parsed form tag of a struct field.
+type fieldTag struct {
+	name  string
+	flags flags
+}
+
+// structTags returns the parsed form tag of each field of the struct type t.
+func structTags(t reflect.Type) []fieldTag {
+	if tags, ok := tagCache.Load(t); ok {
+		return tags.([]fieldTag)
+	}
+
+	tags := make([]fieldTag, t.NumField())
+	for i := range tags {
+		tags[i].name, tags[i].flags = parseTag(t.Field(i).Tag.Get("form"))
+	}
+
+	actual, _ := tagCache.LoadOrStore(t, tags)
+
+	return actual.([]fieldTag)
+}
+
 type flags struct {
-	base64   bool
-	required bool
+	base64       bool
+	required     bool
+	fileprefix   bool
+	space        bool
+	strict       bool
+	gzip         bool
+	readonly     bool
+	checkbox     bool
+	json         bool
+	email        bool
+	flag         bool
+	urldecode    bool
+	csv          bool
+	utf8         bool
+	sanitizeUTF8 bool
+	trim         bool
+	rawquery     bool
+
+	normalizeNewlines bool
+
+	// key is not from the form tag, but the key the field is bound from
+	key string
+
+	// maxSize is not from the form tag, but from the maxsize tag
+	maxSize int64
+
+	// formatField is the sibling string field named by the formatfield tag
+	formatField reflect.Value
 }
 
 func parseTag(tag string) (string, flags) {
@@ -24,6 +79,38 @@ func parseTag(tag string) (string, flags) {
 				f.base64 = true
 			case "required":
 				f.required = true
+			case "fileprefix":
+				f.fileprefix = true
+			case "space":
+				f.space = true
+			case "strict":
+				f.strict = true
+			case "gzip":
+				f.gzip = true
+			case "readonly":
+				f.readonly = true
+			case "checkbox":
+				f.checkbox = true
+			case "json":
+				f.json = true
+			case "email":
+				f.email = true
+			case "flag":
+				f.flag = true
+			case "urldecode":
+				f.urldecode = true
+			case "csv":
+				f.csv = true
+			case "utf8":
+				f.utf8 = true
+			case "sanitizeutf8":
+				f.sanitizeUTF8 = true
+			case "trim":
+				f.trim = true
+			case "normalizenewlines":
+				f.normalizeNewlines = true
+			case "rawquery":
+				f.rawquery = true
 			}
 		}
 
@@ -46,3 +133,65 @@ func base(tag reflect.StructTag) (int, error) {
 
 	return int(base), nil
 }
+
+// maxSize returns the limit from a maxsize tag in bytes, or 0 for no limit.
+func maxSize(tag reflect.StructTag) (int64, error) {
+	sizeStr, ok := tag.Lookup("maxsize")
+	if !ok {
+		return 0, nil
+	}
+
+	size, err := strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil || size < 0 {
+		return 0, errors.New("invalid maxsize")
+	}
+
+	return size, nil
+}
+
+// itemLimits returns the limits from the minitems and maxitems tags, with a
+// max of -1 for no limit.
+func itemLimits(tag reflect.StructTag) (int, int, error) {
+	min, max := 0, -1
+
+	if minStr, ok := tag.Lookup("minitems"); ok {
+		n, err := strconv.Atoi(minStr)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("invalid minitems")
+		}
+
+		min = n
+	}
+
+	if maxStr, ok := tag.Lookup("maxitems"); ok {
+		n, err := strconv.Atoi(maxStr)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("invalid maxitems")
+		}
+
+		max = n
+	}
+
+	return min, max, nil
+}
+
+// snakeCase converts a Go field name like UserID to user_id.
+func snakeCase(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if !unicode.IsUpper(prev) || nextLower {
+				b.WriteByte('_')
+			}
+		}
+
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
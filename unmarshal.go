@@ -1,157 +1,1622 @@
-// Package goform is meant to make binding http data to structs easy.
 package goform
 
 import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"image"
 	"io"
 	"io/ioutil"
+	"math"
+	"math/big"
 	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/mail"
+	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 var (
 	defaultMaxMemory int64 = 32 << 20 // 32 MB
+	defaultMaxDepth        = 32
+
+	// uploadOverhead is how much more than MaxTotalUploadBytes a multipart
+	// body may be, for its text fields and part headers
+	uploadOverhead int64 = 1 << 20 // 1 MB
+
+	defaultTrueValues  = []string{"1", "t", "true", "y", "yes", "on"}
+	defaultFalseValues = []string{"0", "f", "false", "n", "no", "off"}
+
+	units = map[string]time.Duration{
+		"ns": time.Nanosecond,
+		"us": time.Microsecond,
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+	}
 )
 
-// Unmarshal will bind the body and query string values to the given struct.
-// Works will all primitive types, time.Time, image.Image, and []byte.
-// It first inspects the Content-Type header of the request. If the Content-Type
-// is json it will use the json.Unmarshal func and then bind anything from the
-// query string as well.
-func Unmarshal(r *http.Request, v interface{}) error {
-	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
-	if err != nil {
-		return err
+// RequestUnmarshaler is implemented by types that bind themselves from a
+// request. Unmarshal hands the request to UnmarshalRequest instead of binding
+// such types itself, so they need not be structs.
+type RequestUnmarshaler interface {
+	UnmarshalRequest(r *http.Request) error
+}
+
+// Defaulter is implemented by types that set their own default values.
+// Unmarshal calls Defaults before binding, so the request overrides them.
+type Defaulter interface {
+	Defaults()
+}
+
+// Unmarshal will bind the body and query string values to the given struct,
+// which must be passed as a non-nil pointer, even for json bodies. It first
+// inspects the Content-Type header of the request. If the Content-Type is json
+// it will use the json.Unmarshal func and then bind anything from the query
+// string as well, read straight from the request URL so it never depends on
+// the body. Values from the query string override those from the json body,
+// unless the Decoder has JSONOverridesForm set. Urlencoded and multipart
+// bodies are bound along with the query string.
+//
+// The package documentation lists the field types, tags and options Unmarshal
+// understands.
+func Unmarshal(r *http.Request, v interface{}) error {
+	return new(Decoder).Unmarshal(r, v)
+}
+
+// Bind binds the request to a new T, which must be a struct, like Unmarshal,
+// and returns it.
+//
+//	body, err := goform.Bind[MyForm](r)
+func Bind[T any](r *http.Request) (T, error) {
+	var v T
+
+	err := Unmarshal(r, &v)
+
+	return v, err
+}
+
+// UnmarshalPrefixed binds the request to v like Unmarshal, looking up each
+// field's key with prefix in front of it. It lets one struct be bound more than
+// once from a form with several sections, like shipping_city and billing_city.
+// Binding a json body more than once needs a Decoder with BufferBody set.
+//
+//	err := goform.UnmarshalPrefixed(r, &shipping, "shipping_")
+func UnmarshalPrefixed(r *http.Request, v interface{}, prefix string) error {
+	return new(Decoder).UnmarshalPrefixed(r, v, prefix)
+}
+
+// UnmarshalPrefixed binds the request to v like the package level
+// UnmarshalPrefixed, using the options set on d.
+func (d *Decoder) UnmarshalPrefixed(r *http.Request, v interface{}, prefix string) error {
+	c := *d
+	c.prefix = prefix
+
+	return c.Unmarshal(r, v)
+}
+
+// UnmarshalValues will bind the given values to the given struct, the same way
+// Unmarshal binds the query string and form values of a request. It is useful
+// outside of an http handler, where there is no *http.Request.
+func UnmarshalValues(values url.Values, v interface{}) error {
+	return new(Decoder).UnmarshalValues(values, v)
+}
+
+// Validate binds the request to a new zero value of the type v points to,
+// leaving v itself untouched, and returns the error Unmarshal would have. It
+// lets a handler check a request before committing to process it. Note that
+// the request body is read either way.
+func Validate(r *http.Request, v interface{}) error {
+	return new(Decoder).Validate(r, v)
+}
+
+// Validate checks the request against v like the package level Validate,
+// using the options set on d.
+func (d *Decoder) Validate(r *http.Request, v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr {
+		return errors.New("goform: v must be a pointer")
+	}
+
+	return d.Unmarshal(r, reflect.New(val.Type().Elem()).Interface())
+}
+
+// Unmarshal binds the request to v like the package level Unmarshal, using the
+// options set on d.
+func (d *Decoder) Unmarshal(r *http.Request, v interface{}) error {
+	err := checkPointer(v)
+	if err != nil {
+		return err
+	}
+
+	// a RequestUnmarshaler binds itself, so it need not be a struct
+	if u, ok := v.(RequestUnmarshaler); ok {
+		return u.UnmarshalRequest(r)
+	}
+
+	err = checkTarget(v)
+	if err != nil {
+		return err
+	}
+
+	if def, ok := v.(Defaulter); ok {
+		def.Defaults()
+	}
+
+	if d.DisallowBodyForEmptyStruct && reflect.TypeOf(v).Elem().NumField() == 0 && hasBody(r) {
+		return errors.New("goform: request has a body, but v has no fields to bind it to")
+	}
+
+	if d.UseTimeZoneHeader {
+		c := *d
+		c.location = headerLocation(r)
+		d = &c
+	}
+
+	if d.QueryOnlyForBodylessMethods && isBodylessMethod(r.Method) {
+		query := r.URL.Query()
+		return d.bind(source{method: r.Method, values: query, query: query}, v)
+	}
+
+	if d.MaxContentLength > 0 && r.ContentLength > d.MaxContentLength {
+		return fmt.Errorf("goform: content length exceeds %d bytes", d.MaxContentLength)
+	}
+
+	// without a Content-Type there is no body to bind, just the query string
+	var mediaType string
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return err
+		}
+
+		if !d.allowsContentType(mediaType) {
+			return fmt.Errorf("goform: unsupported content type %s", mediaType)
+		}
+	}
+
+	if d.BufferBody && r.Body != nil {
+		data, err := d.readBody(r.Body, mediaType == "multipart/form-data")
+		r.Body.Close() // nolint
+		if err != nil {
+			return err
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+		// whatever binding reads, the next reader gets the whole body again
+		defer func() {
+			r.Body = ioutil.NopCloser(bytes.NewReader(data))
+		}()
+	}
+
+	// requests built for tests or clients may have no body at all
+	if r.Body != nil {
+		defer r.Body.Close()
+	}
+
+	isJSON := mediaType == "application/json"
+
+	src := source{
+		method:    r.Method,
+		query:     r.URL.Query(),
+		multipart: mediaType == "multipart/form-data",
+	}
+
+	var rawJSON json.RawMessage
+
+	if isJSON {
+		body := r.Body
+		if body == nil {
+			body = http.NoBody
+		}
+
+		rawJSON, err = d.readJSON(body)
+		if err != nil {
+			return d.bodyError(r, v, err)
+		}
+
+		src.json = jsonKeys(rawJSON)
+	}
+
+	if isJSON && !d.JSONOverridesForm {
+		err = d.decodeBody(src, rawJSON, v)
+		if err != nil {
+			return d.bodyError(r, v, err)
+		}
+	}
+
+	switch {
+	case isJSON:
+		// the body belongs to the json decoder, so only the query string is
+		// left to bind
+		src.values = src.query
+	case src.multipart:
+		err = d.parseMultipartForm(r)
+		if errors.Is(err, errUploadTooLarge) {
+			return fmt.Errorf("goform: total upload size exceeds %d bytes", d.MaxTotalUploadBytes)
+		}
+
+		// an empty body has no parts, so every field is treated as absent
+		if err != nil && !errors.Is(err, io.EOF) {
+			return d.bodyError(r, v, err)
+		}
+
+		src.values = r.Form
+
+		if r.MultipartForm != nil {
+			src.files = r.MultipartForm.File
+			src.form = r.MultipartForm
+		}
+
+		err = d.checkUploadSize(src.files)
+		if err != nil {
+			return err
+		}
+	default:
+		// ParseForm parses the query string for any content type, and only
+		// reads the body when it is urlencoded
+		r.ParseForm() // nolint
+
+		src.values = r.Form
+	}
+
+	err = d.bind(src, v)
+	if err != nil {
+		return err
+	}
+
+	if isJSON && d.JSONOverridesForm {
+		err = d.decodeBody(src, rawJSON, v)
+		if err != nil {
+			return d.bodyError(r, v, err)
+		}
+	}
+
+	return nil
+}
+
+// bodyError returns err, the error from parsing the body of r. With
+// BindQueryOnBodyError, the query string is bound to v first, and err is
+// returned as a *BodyError, unless binding the query string fails too.
+func (d *Decoder) bodyError(r *http.Request, v interface{}, err error) error {
+	if !d.BindQueryOnBodyError {
+		return err
+	}
+
+	query := r.URL.Query()
+
+	bindErr := d.bind(source{method: r.Method, values: query, query: query}, v)
+	if bindErr != nil {
+		return bindErr
+	}
+
+	return &BodyError{Err: err}
+}
+
+// UnmarshalValues binds values to v like the package level UnmarshalValues,
+// using the options set on d.
+func (d *Decoder) UnmarshalValues(values url.Values, v interface{}) error {
+	err := checkTarget(v)
+	if err != nil {
+		return err
+	}
+
+	if def, ok := v.(Defaulter); ok {
+		def.Defaults()
+	}
+
+	return d.bind(source{values: values}, v)
+}
+
+// checkPointer makes sure v is a non-nil pointer, which is all a
+// RequestUnmarshaler needs to be.
+func checkPointer(v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr {
+		return errors.New("goform: v must be a pointer")
+	}
+
+	if val.IsNil() {
+		return errors.New("goform: v must be a non-nil pointer to struct")
+	}
+
+	return nil
+}
+
+// checkTarget makes sure v is something the request can be bound to.
+func checkTarget(v interface{}) error {
+	err := checkPointer(v)
+	if err != nil {
+		return err
+	}
+
+	if reflect.ValueOf(v).Elem().Kind() != reflect.Struct {
+		return errors.New("goform: v must be a non-nil pointer to struct")
+	}
+
+	return nil
+}
+
+// parseMultipartForm parses the multipart body of r. With MaxTotalUploadBytes
+// set, it stops reading, with errUploadTooLarge, once the body is larger than
+// the limit plus uploadOverhead.
+func (d *Decoder) parseMultipartForm(r *http.Request) error {
+	if d.MaxTotalUploadBytes <= 0 || r.Body == nil {
+		return r.ParseMultipartForm(defaultMaxMemory)
+	}
+
+	body := r.Body
+	r.Body = &limitedBody{ReadCloser: body, n: d.MaxTotalUploadBytes + uploadOverhead}
+
+	defer func() { r.Body = body }()
+
+	return r.ParseMultipartForm(defaultMaxMemory)
+}
+
+// readBody reads all of body for BufferBody. It stops with the error binding
+// would have given once body is larger than MaxContentLength, or, for a
+// multipart body, than MaxTotalUploadBytes plus uploadOverhead.
+func (d *Decoder) readBody(body io.Reader, multipart bool) ([]byte, error) {
+	limit := d.MaxContentLength
+	limitErr := fmt.Errorf("goform: content length exceeds %d bytes", d.MaxContentLength)
+
+	if multipart && d.MaxTotalUploadBytes > 0 && (limit <= 0 || d.MaxTotalUploadBytes+uploadOverhead < limit) {
+		limit = d.MaxTotalUploadBytes + uploadOverhead
+		limitErr = fmt.Errorf("goform: total upload size exceeds %d bytes", d.MaxTotalUploadBytes)
+	}
+
+	if limit <= 0 {
+		return ioutil.ReadAll(body)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		return nil, limitErr
+	}
+
+	return data, nil
+}
+
+// errUploadTooLarge is returned by a limitedBody read past its limit.
+var errUploadTooLarge = errors.New("goform: upload too large")
+
+// limitedBody is a request body that fails with errUploadTooLarge once more
+// than n bytes are read from it.
+type limitedBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.n < 0 {
+		return 0, errUploadTooLarge
+	}
+
+	// one byte past the limit tells a body that is too large from one that
+	// fits exactly
+	if int64(len(p)) > b.n+1 {
+		p = p[:b.n+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.n {
+		b.n -= int64(n)
+		return n, err
+	}
+
+	n = int(b.n)
+	b.n = -1
+
+	return n, errUploadTooLarge
+}
+
+// checkUploadSize makes sure the uploaded files fit in MaxTotalUploadBytes.
+// It runs once the body is parsed, since only then are the files' sizes
+// known.
+func (d *Decoder) checkUploadSize(files map[string][]*multipart.FileHeader) error {
+	if d.MaxTotalUploadBytes <= 0 {
+		return nil
+	}
+
+	var total int64
+	for _, headers := range files {
+		for _, hdr := range headers {
+			total += hdr.Size
+		}
+	}
+
+	if total > d.MaxTotalUploadBytes {
+		return fmt.Errorf("goform: total upload size exceeds %d bytes", d.MaxTotalUploadBytes)
+	}
+
+	return nil
+}
+
+// hasBody reports whether r has a non-empty body, reading from it when the
+// length isn't known.
+func hasBody(r *http.Request) bool {
+	if r.Body == nil || r.ContentLength == 0 {
+		return false
+	}
+
+	if r.ContentLength > 0 {
+		return true
+	}
+
+	n, _ := r.Body.Read(make([]byte, 1))
+
+	return n > 0
+}
+
+// headerLocation returns the location named by the Time-Zone header of r,
+// falling back to UTC.
+func headerLocation(r *http.Request) *time.Location {
+	tz := r.Header.Get("Time-Zone")
+	if tz == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// isBodylessMethod reports whether requests using method should not carry a
+// body.
+func isBodylessMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodDelete
+}
+
+// allowsMethod reports whether method is in the comma separated list of
+// methods from a methods tag. Without a request there is no method to check.
+func allowsMethod(methods, method string) bool {
+	if method == "" {
+		return true
+	}
+
+	for _, m := range strings.Split(methods, ",") {
+		if strings.EqualFold(strings.TrimSpace(m), method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// source holds the values and uploaded files a struct is bound from.
+type source struct {
+	method    string
+	values    url.Values
+	query     url.Values
+	rawQuery  url.Values
+	files     map[string][]*multipart.FileHeader
+	form      *multipart.Form
+	multipart bool
+
+	// json has the lower cased keys of the json body, if there was one
+	json map[string]bool
+
+	// prefix is prepended to the keys of a nested struct's fields, and depth
+	// counts how deeply nested it is
+	prefix string
+	depth  int
+
+	// known records the keys fields are bound from, when unknown keys are
+	// reported
+	known *knownKeys
+}
+
+// subKeys returns the sorted keys of values, and files, that start with
+// prefix, with the prefix removed. With first, only the first segment of each
+// key up to a dot is returned, once.
+func (src source) subKeys(prefix string, first bool) []string {
+	seen := map[string]bool{}
+
+	add := func(key string) {
+		if !strings.HasPrefix(key, prefix) {
+			return
+		}
+
+		key = strings.TrimPrefix(key, prefix)
+		if first {
+			key = strings.SplitN(key, ".", 2)[0]
+		}
+
+		seen[key] = true
+	}
+
+	for key := range src.values {
+		add(key)
+	}
+
+	for key := range src.files {
+		add(key)
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// hasPrefix reports whether any value or file has a key starting with prefix.
+func (src source) hasPrefix(prefix string) bool {
+	for key := range src.values {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	for key := range src.files {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inJSON reports whether the json body had a value for f.
+func (src source) inJSON(f reflect.StructField) bool {
+	name := jsonName(f)
+	return name != "-" && src.json[strings.ToLower(name)]
+}
+
+// origin describes where the values for key came from, for logging.
+func (src source) origin(key string) string {
+	switch {
+	case src.query == nil:
+		return "values"
+	case len(src.query[key]) > 0:
+		return "query"
+	default:
+		return "form"
+	}
+}
+
+func (d *Decoder) bind(src source, v interface{}) error {
+	src.prefix = d.prefix
+	src.rawQuery = src.query
+	src.values = normalizeKeys(src.values)
+	src.query = normalizeKeys(src.query)
+	src.files = normalizeKeys(src.files)
+
+	if d.unknownFields != nil {
+		src.known = &knownKeys{keys: map[string]bool{}}
+	}
+
+	val := reflect.Indirect(reflect.ValueOf(v))
+
+	err := d.bindStruct(src, val)
+	if err == nil && src.known != nil {
+		*d.unknownFields = src.known.unknown(src, val.Type())
+	}
+
+	return err
+}
+
+// normalizeKeys rewrites bracketed keys to dotted ones, like user[address][city]
+// to user.address.city. Trailing empty brackets, like tags[], are dropped, so
+// their values are repeated values of tags. The values of keys that normalize
+// to the same key, like tags and tags[], are combined in the order of the keys.
+func normalizeKeys[T any](m map[string][]T) map[string][]T {
+	bracketed := false
+	for key := range m {
+		if strings.Contains(key, "[") {
+			bracketed = true
+			break
+		}
+	}
+
+	if !bracketed {
+		return m
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	// map iteration order is random, so sort to keep combined values in a
+	// stable order
+	sort.Strings(keys)
+
+	normalized := make(map[string][]T, len(m))
+	for _, key := range keys {
+		name := normalizeKey(key)
+		normalized[name] = append(normalized[name], m[key]...)
+	}
+
+	return normalized
+}
+
+// normalizeKey rewrites a single bracketed key to a dotted one. Keys that
+// aren't well formed, like a[b, are left alone.
+func normalizeKey(key string) string {
+	i := strings.Index(key, "[")
+	if i <= 0 {
+		return key
+	}
+
+	parts := []string{key[:i]}
+
+	rest := key[i:]
+	for rest != "" {
+		end := strings.Index(rest, "]")
+		if rest[0] != '[' || end < 0 {
+			return key
+		}
+
+		parts = append(parts, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	if parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+
+	for _, part := range parts[1:] {
+		if part == "" {
+			return key
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func (d *Decoder) bindStruct(src source, val reflect.Value) error {
+	t := val.Type()
+
+	var errs MultiError
+
+	for i, fieldTag := range structTags(t) {
+		f := t.Field(i)
+
+		tag, tagOptions := fieldTag.name, fieldTag.flags
+
+		// the whole query string is bound, so the field has no key
+		if tagOptions.rawquery {
+			err := decodeRawQuery(src, val.Field(i))
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if tag == "" {
+			tag = d.fieldName(f)
+		}
+
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		// a * field takes the keys under the prefix, so the sentinel itself
+		// isn't prefixed
+		if tag == "*" {
+			src.known.addPrefix(src.prefix)
+		} else {
+			tag = src.prefix + normalizeKey(tag)
+			src.known.add(tag, f.Type, tagOptions)
+		}
+
+		if methods, ok := f.Tag.Lookup("methods"); ok && !allowsMethod(methods, src.method) {
+			continue
+		}
+
+		// a required field is satisfied by the json body too
+		if tagOptions.required && src.inJSON(f) {
+			tagOptions.required = false
+		}
+
+		var err error
+
+		switch {
+		case tagOptions.readonly || d.blocksField(tag):
+			d.log("goform: field is read only", "field", f.Name, "key", tag)
+		case !d.allowsField(tag):
+			err = d.skipField(src, f, tag)
+		case d.ErrorOnConflict && src.inJSON(f) && (len(src.values[tag]) > 0 || len(src.files[tag]) > 0):
+			err = &codedError{code: ErrConflict, msg: fmt.Sprintf("goform: field [%s] is set by both the json body and the form", tag)}
+		default:
+			err = d.bindField(src, val, f, tag, tagOptions)
+			if err == nil {
+				err = checkItems(val.Field(i), f, tag)
+			}
+		}
+		if err == nil {
+			continue
+		}
+
+		// the errors of a nested struct's fields are already collected
+		if multiErr, ok := err.(MultiError); ok {
+			errs = append(errs, multiErr...)
+			continue
+		}
+
+		fieldErr := newFieldError(tag, err)
+		if msg, ok := f.Tag.Lookup("errmsg"); ok {
+			fieldErr.Err = errors.New(msg)
+		}
+
+		if !d.CollectErrors {
+			return fieldErr
+		}
+
+		errs = append(errs, fieldErr)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// isNested reports whether t is a struct, or a pointer to one, whose fields
+// are bound from keys prefixed with the key of the field holding it.
+func (d *Decoder) isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+
+	if _, ok := d.parsers[t]; ok {
+		return false
+	}
+
+	pt := reflect.PtrTo(t)
+
+	return !pt.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) && !pt.Implements(imageType) &&
+		!pt.Implements(reflect.TypeOf((*sql.Scanner)(nil)).Elem())
+}
+
+// bindNested binds the fields of a nested struct from keys like
+// address.street, where address is the key of the struct's field. A pointer
+// to a struct is only allocated when there are keys for it.
+func (d *Decoder) bindNested(src source, valf reflect.Value, key string) error {
+	prefix := key + "."
+
+	if !src.hasPrefix(prefix) && valf.Kind() == reflect.Ptr {
+		return nil
+	}
+
+	maxDepth := d.maxDepth()
+
+	if src.depth >= maxDepth {
+		if src.hasPrefix(prefix) {
+			return fmt.Errorf("goform: field [%s] is nested more than %d levels deep", key, maxDepth)
+		}
+
+		return nil
+	}
+
+	if valf.Kind() == reflect.Ptr {
+		if valf.IsNil() {
+			valf.Set(reflect.New(valf.Type().Elem()))
+		}
+
+		valf = valf.Elem()
+	}
+
+	// the json body's keys only describe the top level struct
+	src.json = nil
+	src.prefix = prefix
+	src.depth++
+
+	return d.bindStruct(src, valf)
+}
+
+// skipField leaves a field AllowedFields doesn't include alone, returning an
+// error if the request has a value for it and StrictAllowedFields is set.
+func (d *Decoder) skipField(src source, f reflect.StructField, tag string) error {
+	if d.StrictAllowedFields && (len(src.values[tag]) > 0 || len(src.files[tag]) > 0) {
+		return fmt.Errorf("goform: field [%s] is not allowed", tag)
+	}
+
+	d.log("goform: field not allowed", "field", f.Name, "key", tag)
+
+	return nil
+}
+
+func (d *Decoder) bindField(src source, val reflect.Value, f reflect.StructField, tag string, tagOptions flags) error {
+	// unexported fields can't be set directly, only through a setter
+	if f.PkgPath != "" {
+		if d.UseSetters {
+			return d.bindSetter(src, val, f, tag, tagOptions)
+		}
+
+		return nil
+	}
+
+	valf := val.FieldByName(f.Name)
+	kind := f.Type.Kind()
+
+	tagOptions.key = tag
+
+	if tag == "*" {
+		return decodeAll(src, valf)
+	}
+
+	if tagOptions.checkbox {
+		if kind != reflect.Bool {
+			return errors.New("goform: checkbox option requires a bool field")
+		}
+
+		// an unchecked checkbox isn't submitted at all, which means false
+		if len(src.values[tag]) == 0 && !src.inJSON(f) {
+			valf.SetBool(false)
+			return nil
+		}
+	}
+
+	if tagOptions.flag {
+		if kind != reflect.Bool {
+			return errors.New("goform: flag option requires a bool field")
+		}
+
+		formValues, present := src.values[tag]
+
+		switch {
+		case !present && src.inJSON(f):
+		case !present:
+			valf.SetBool(false)
+		case len(formValues) == 0 || formValues[0] == "":
+			// the key alone, like ?verbose, turns the flag on
+			valf.SetBool(true)
+		default:
+			return d.decodeBool(valf, formValues[0])
+		}
+
+		return nil
+	}
+
+	if !tagOptions.json && d.isNested(f.Type) {
+		return d.bindNested(src, valf, tag)
+	}
+
+	// pointers to concrete images are set directly, not allocated
+	if kind == reflect.Ptr && !f.Type.Implements(imageType) {
+		// maps and slices of structs may only have keys under their own, like
+		// scores.1 or items.0.name
+		elemKind := f.Type.Elem().Kind()
+		keyed := (elemKind == reflect.Map || elemKind == reflect.Slice) && src.hasPrefix(tag+".")
+
+		// without a value for it the pointer is left nil
+		if !tagOptions.fileprefix && !keyed && len(src.values[tag]) == 0 && len(src.files[tag]) == 0 {
+			if tagOptions.required {
+				return missingRequired(tag, src.multipart && isFileType(f.Type.Elem()))
+			}
+
+			d.log("goform: field not present", "field", f.Name, "key", tag)
+			return nil
+		}
+
+		kind = f.Type.Elem().Kind()
+		valf.Set(reflect.New(f.Type.Elem()))
+		valf = reflect.Indirect(valf)
+	}
+
+	var err error
+
+	tagOptions.maxSize, err = maxSize(f.Tag)
+	if err != nil {
+		return err
+	}
+
+	if name, ok := f.Tag.Lookup("formatfield"); ok {
+		tagOptions.formatField = val.FieldByName(name)
+		if !tagOptions.formatField.IsValid() || tagOptions.formatField.Kind() != reflect.String {
+			return fmt.Errorf("goform: formatfield %q must name a string field", name)
+		}
+
+		if !tagOptions.formatField.CanSet() {
+			return fmt.Errorf("goform: formatfield %q must name an exported field", name)
+		}
+	}
+
+	if tagOptions.fileprefix {
+		return d.decodeMultipartPrefix(src, strings.TrimSuffix(tag, "*"), valf, tagOptions)
+	}
+
+	formValues := src.values[tag]
+
+	if len(formValues) == 0 && src.hasPrefix(tag+".") {
+		switch {
+		case kind == reflect.Map:
+			return d.decodeKeyedMap(src, tag, valf, f, tagOptions)
+		case kind == reflect.Slice && d.isNested(valf.Type().Elem()):
+			return d.decodeIndexedSlice(src, tag, valf)
+		}
+	}
+
+	if len(formValues) == 0 {
+		if len(src.files[tag]) == 0 {
+			d.log("goform: field not present", "field", f.Name, "key", tag)
+		} else {
+			d.log("goform: field bound", "field", f.Name, "key", tag, "source", "multipart")
+		}
+
+		return d.decodeMultipart(src, tag, valf, kind, tagOptions)
+	}
+
+	if tagOptions.urldecode {
+		formValues, err = unescapeValues(formValues)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tagOptions.space {
+		if kind != reflect.Slice {
+			return errors.New("goform: space option requires a slice field")
+		}
+
+		formValues = splitFields(formValues)
+	}
+
+	sep, ok := f.Tag.Lookup("sep")
+	if tagOptions.csv {
+		sep, ok = ",", true
+	}
+
+	if ok && sep != "" {
+		if kind != reflect.Slice && tagOptions.csv {
+			return errors.New("goform: csv option requires a slice field")
+		}
+
+		if kind != reflect.Slice {
+			return errors.New("goform: sep tag requires a slice field")
+		}
+
+		formValues = splitSep(formValues, sep)
+	}
+
+	switch {
+	case tagOptions.json:
+		if len(formValues) > 1 && !d.UseFirstValue {
+			return errors.New("goform: arrays not supported yet")
+		}
+
+		err = json.Unmarshal([]byte(formValues[0]), valf.Addr().Interface())
+	case kind == reflect.Map:
+		err = decodeMap(valf, formValues)
+	case kind == reflect.Slice && !d.isScalar(valf.Type()):
+		prev := reflect.ValueOf(valf.Interface())
+
+		err = d.decodeSlice(valf, f, tagOptions, formValues)
+		if err == nil && d.MergeSlices && src.inJSON(f) {
+			valf.Set(reflect.AppendSlice(prev, valf))
+		}
+	default:
+		if len(formValues) > 1 && !d.UseFirstValue {
+			return errors.New("goform: arrays not supported yet")
+		}
+
+		err = d.decodeFormValue(valf, kind, f, tagOptions, formValues[0])
+		if err == nil {
+			err = d.checkOneOf(valf, f, tagOptions)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.log("goform: field bound", "field", f.Name, "key", tag, "source", src.origin(tag), "value", formValues)
+
+	return nil
+}
+
+// bindSetter binds a field by calling its Set<Field>(string) error method.
+func (d *Decoder) bindSetter(src source, val reflect.Value, f reflect.StructField, tag string, tagOptions flags) error {
+	name := "Set" + strings.ToUpper(f.Name[:1]) + f.Name[1:]
+
+	method := val.Addr().MethodByName(name)
+	if !method.IsValid() {
+		return fmt.Errorf("goform: field %s has no %s method", f.Name, name)
+	}
+
+	set, ok := method.Interface().(func(string) error)
+	if !ok {
+		return fmt.Errorf("goform: %s must be a func(string) error", name)
+	}
+
+	formValues := src.values[tag]
+
+	if len(formValues) == 0 {
+		if tagOptions.required {
+			return missingRequired(tag, false)
+		}
+
+		return nil
+	}
+
+	if len(formValues) > 1 && !d.UseFirstValue {
+		return errors.New("goform: arrays not supported yet")
+	}
+
+	return set(formValues[0])
+}
+
+func (d *Decoder) decodeFormValue(valf reflect.Value, kind reflect.Kind, f reflect.StructField, tagOptions flags, formValue string) error {
+	var err error
+
+	if parse, ok := d.parsers[valf.Type()]; ok {
+		parsed, err := parse(formValue)
+		if err != nil {
+			return err
+		}
+
+		valf.Set(parsed)
+		return nil
+	}
+
+	if scale, ok := f.Tag.Lookup("scale"); ok {
+		formValue, err = roundDecimal(formValue, scale)
+		if err != nil {
+			return err
+		}
+	}
+
+	if valf.CanAddr() && valf.Type() != reflect.TypeOf(time.Time{}) {
+		if u, ok := valf.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(formValue))
+		}
+	}
+
+	if unit, ok := f.Tag.Lookup("unit"); ok {
+		return decodeUnit(valf, kind, unit, formValue)
+	}
+
+	if endian, ok := f.Tag.Lookup("endian"); ok {
+		return decodeEndian(valf, kind, tagOptions, endian, formValue)
+	}
+
+	if format, ok := f.Tag.Lookup("format"); ok && valf.Type() == reflect.TypeOf(time.Duration(0)) {
+		return decodeTimeOfDay(valf, format, formValue)
+	}
+
+	switch kind {
+	case reflect.Slice:
+		if valf.Type() == reflect.TypeOf([]byte{}) {
+			limit, err := maxSize(f.Tag)
+			if err != nil {
+				return err
+			}
+
+			if limit > 0 && int64(len(formValue)) > limit {
+				return outOfRange("goform: value exceeds maxsize of %d bytes", limit)
+			}
+
+			valf.SetBytes([]byte(formValue))
+		}
+		break
+	case reflect.String:
+		if valf.Type() == reflect.TypeOf(json.Number("")) && !isJSONNumber(formValue) {
+			return fmt.Errorf("goform: invalid number %q", formValue)
+		}
+
+		switch {
+		case tagOptions.sanitizeUTF8:
+			formValue = strings.ToValidUTF8(formValue, string(utf8.RuneError))
+		case tagOptions.utf8 && !utf8.ValidString(formValue):
+			return fmt.Errorf("goform: invalid utf-8 in %q", formValue)
+		}
+
+		if tagOptions.trim {
+			formValue = strings.TrimSpace(formValue)
+		}
+
+		if tagOptions.normalizeNewlines {
+			formValue = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(formValue)
+		}
+
+		if tagOptions.email && !isEmail(formValue) {
+			return fmt.Errorf("goform: invalid email address %q", formValue)
+		}
+		valf.SetString(formValue)
+	case reflect.Bool:
+		err = d.decodeBool(valf, formValue)
+	case reflect.Int:
+		err = decodeInt(valf, f.Tag, 0, formValue)
+	case reflect.Int8:
+		err = decodeInt(valf, f.Tag, 8, formValue)
+	case reflect.Int16:
+		err = decodeInt(valf, f.Tag, 16, formValue)
+	case reflect.Int32:
+		err = decodeInt(valf, f.Tag, 32, formValue)
+	case reflect.Int64:
+		err = decodeInt(valf, f.Tag, 64, formValue)
+	case reflect.Uint:
+		err = decodeUint(valf, f.Tag, 0, formValue)
+	case reflect.Uint8:
+		err = decodeUint(valf, f.Tag, 8, formValue)
+	case reflect.Uint16:
+		err = decodeUint(valf, f.Tag, 16, formValue)
+	case reflect.Uint32:
+		err = decodeUint(valf, f.Tag, 32, formValue)
+	case reflect.Uint64:
+		err = decodeUint(valf, f.Tag, 64, formValue)
+	case reflect.Float32:
+		err = decodeFloat(valf, 32, formValue)
+	case reflect.Float64:
+		err = decodeFloat(valf, 64, formValue)
+	case reflect.Struct:
+		err = d.decodeStruct(valf, f, tagOptions, formValue)
+	case reflect.Array:
+		err = decodeByteArray(valf, tagOptions, formValue)
+	case reflect.Uintptr:
+		err = fmt.Errorf("goform: field %s is a uintptr, which holds a memory address and can't be bound", f.Name)
+	default:
+		err = errors.New("goform: invalid destination type")
+	}
+
+	return err
+}
+
+// checkOneOf makes sure a decoded value is one of the space separated values
+// in the field's oneof tag, comparing them once decoded, so 01 matches 1 for
+// an int.
+func (d *Decoder) checkOneOf(valf reflect.Value, f reflect.StructField, tagOptions flags) error {
+	options, ok := f.Tag.Lookup("oneof")
+	if !ok {
+		return nil
+	}
+
+	for _, option := range strings.Fields(options) {
+		allowed := reflect.New(valf.Type()).Elem()
+
+		err := d.decodeFormValue(allowed, allowed.Kind(), f, tagOptions, option)
+		if err != nil {
+			return fmt.Errorf("goform: invalid oneof value %q: %w", option, err)
+		}
+
+		if reflect.DeepEqual(allowed.Interface(), valf.Interface()) {
+			return nil
+		}
+	}
+
+	return outOfRange("goform: value must be one of %s", options)
+}
+
+// checkItems makes sure a slice field has no fewer elements than its minitems
+// tag, and no more than its maxitems tag. Empty slices are left to the
+// required option.
+func checkItems(valf reflect.Value, f reflect.StructField, tag string) error {
+	valf = reflect.Indirect(valf)
+	if valf.Kind() != reflect.Slice || valf.Len() == 0 {
+		return nil
+	}
+
+	min, max, err := itemLimits(f.Tag)
+	if err != nil {
+		return err
+	}
+
+	if valf.Len() < min {
+		return outOfRange("goform: field [%s] has %d items, fewer than minitems of %d", tag, valf.Len(), min)
+	}
+
+	if max >= 0 && valf.Len() > max {
+		return outOfRange("goform: field [%s] has %d items, more than maxitems of %d", tag, valf.Len(), max)
+	}
+
+	return nil
+}
+
+// decodeByteArray binds a hex value, or a base64 one with the base64 option,
+// to a fixed size byte array like a [32]byte digest. The decoded value must
+// fill the array exactly.
+func decodeByteArray(valf reflect.Value, tagOptions flags, formValue string) error {
+	if valf.Type().Elem().Kind() != reflect.Uint8 {
+		return errors.New("goform: invalid destination type")
+	}
+
+	data, err := decodeBinary(tagOptions, formValue)
+	if err != nil {
+		return err
+	}
+
+	if len(data) != valf.Len() {
+		return fmt.Errorf("goform: value has %d bytes, expected %d", len(data), valf.Len())
+	}
+
+	reflect.Copy(valf, reflect.ValueOf(data))
+
+	return nil
+}
+
+// decodeBinary decodes a hex value, or a base64 one with the base64 option.
+func decodeBinary(tagOptions flags, formValue string) ([]byte, error) {
+	if tagOptions.base64 {
+		return base64.StdEncoding.DecodeString(formValue)
+	}
+
+	return hex.DecodeString(formValue)
+}
+
+// decodeEndian binds the bytes of a hex or base64 value to an integer field,
+// read in the byte order named by endian. There must be exactly as many bytes
+// as the field's type holds.
+func decodeEndian(valf reflect.Value, kind reflect.Kind, tagOptions flags, endian, formValue string) error {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return errors.New("goform: endian tag requires an integer field")
+	}
+
+	if endian != "big" && endian != "little" {
+		return fmt.Errorf("goform: invalid endian %q", endian)
+	}
+
+	data, err := decodeBinary(tagOptions, formValue)
+	if err != nil {
+		return err
+	}
+
+	size := int(valf.Type().Size())
+	if len(data) != size {
+		return fmt.Errorf("goform: value has %d bytes, expected %d", len(data), size)
+	}
+
+	var n uint64
+	for i := range data {
+		b := data[i]
+		if endian == "little" {
+			b = data[len(data)-1-i]
+		}
+
+		n = n<<8 | uint64(b)
+	}
+
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		valf.SetUint(n)
+	default:
+		// sign extended from the field's size
+		shift := uint(64 - 8*size)
+		valf.SetInt(int64(n<<shift) >> shift)
+	}
+
+	return nil
+}
+
+// isScalar reports whether a slice type t is decoded from a single value,
+// rather than an element per value.
+func (d *Decoder) isScalar(t reflect.Type) bool {
+	if t == reflect.TypeOf([]byte{}) {
+		return true
+	}
+
+	if _, ok := d.parsers[t]; ok {
+		return true
+	}
+
+	return reflect.PtrTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// unescapeValues decodes values that are still percent encoded, like those
+// from clients that encode them twice.
+func unescapeValues(formValues []string) ([]string, error) {
+	unescaped := make([]string, len(formValues))
+
+	for i, formValue := range formValues {
+		var err error
+
+		unescaped[i], err = url.QueryUnescape(formValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return unescaped, nil
+}
+
+// splitFields splits each value on whitespace, like an OAuth scope.
+func splitFields(formValues []string) []string {
+	var words []string
+	for _, formValue := range formValues {
+		words = append(words, strings.Fields(formValue)...)
+	}
+
+	return words
+}
+
+// splitSep splits each value on sep, dropping empty segments.
+func splitSep(formValues []string, sep string) []string {
+	var parts []string
+	for _, formValue := range formValues {
+		for _, part := range strings.Split(formValue, sep) {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+
+	return parts
+}
+
+// decodeSlice binds each value to an element of a slice, using the same tags
+// as the field itself.
+func (d *Decoder) decodeSlice(valf reflect.Value, f reflect.StructField, tagOptions flags, formValues []string) error {
+	slice := reflect.MakeSlice(valf.Type(), len(formValues), len(formValues))
+
+	for i, formValue := range formValues {
+		elem := slice.Index(i)
+		kind := elem.Kind()
+
+		if kind == reflect.Ptr {
+			elem.Set(reflect.New(elem.Type().Elem()))
+			elem = elem.Elem()
+			kind = elem.Kind()
+		}
+
+		err := d.decodeFormValue(elem, kind, f, tagOptions, formValue)
+		if err == nil {
+			err = d.checkOneOf(elem, f, tagOptions)
+		}
+		if err != nil {
+			return fmt.Errorf("%w (field [%s], element %d)", err, tagOptions.key, i)
+		}
+	}
+
+	valf.Set(slice)
+
+	return nil
+}
+
+// decodeRawQuery copies the query string, with its keys as sent, to a
+// url.Values field with the rawquery option.
+func decodeRawQuery(src source, valf reflect.Value) error {
+	t := valf.Type()
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String ||
+		t.Elem() != reflect.TypeOf([]string{}) {
+		return errors.New("goform: rawquery option requires a url.Values field")
+	}
+
+	if src.rawQuery == nil {
+		return nil
+	}
+
+	m := reflect.MakeMapWithSize(t, len(src.rawQuery))
+	for key, values := range src.rawQuery {
+		m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), reflect.ValueOf(append([]string(nil), values...)))
+	}
+
+	valf.Set(m)
+
+	return nil
+}
+
+// decodeAll copies every value with a key under the prefix to a
+// map[string][]string, keyed without the prefix, for fields tagged with the *
+// sentinel.
+func decodeAll(src source, valf reflect.Value) error {
+	t := valf.Type()
+
+	if t == reflect.TypeOf((*multipart.Form)(nil)) {
+		if src.form != nil {
+			valf.Set(reflect.ValueOf(src.form))
+		}
+
+		return nil
 	}
 
-	defer r.Body.Close()
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String ||
+		t.Elem().Kind() != reflect.Slice || t.Elem().Elem().Kind() != reflect.String {
+		return errors.New("goform: * requires a map[string][]string field")
+	}
 
-	if mediaType == "application/json" {
-		err = json.NewDecoder(r.Body).Decode(v)
-		if err != nil {
-			return err
+	m := reflect.MakeMap(t)
+	for key, values := range src.values {
+		if !strings.HasPrefix(key, src.prefix) {
+			continue
 		}
+
+		key = strings.TrimPrefix(key, src.prefix)
+		m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), reflect.ValueOf(append([]string(nil), values...)).Convert(t.Elem()))
 	}
 
-	t := reflect.TypeOf(v)
-	if t.Kind() != reflect.Ptr {
-		return errors.New("goform: v must be a pointer")
+	if m.Len() > 0 {
+		valf.Set(m)
 	}
 
-	t = t.Elem()
-	val := reflect.Indirect(reflect.ValueOf(v))
+	return nil
+}
+
+// decodeMap binds repeated values, like those from a group of checkboxes, to a
+// map[string]bool or map[string]struct{} with an entry for each value.
+func decodeMap(valf reflect.Value, formValues []string) error {
+	t := valf.Type()
+	if t.Key().Kind() != reflect.String {
+		return errors.New("goform: invalid destination type")
+	}
 
-	r.ParseMultipartForm(defaultMaxMemory) // nolint
+	var elem reflect.Value
 
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		tag, tagOptions := parseTag(f.Tag.Get("form"))
+	switch {
+	case t.Elem().Kind() == reflect.Bool:
+		elem = reflect.ValueOf(true).Convert(t.Elem())
+	case t.Elem().Kind() == reflect.Struct && t.Elem().NumField() == 0:
+		elem = reflect.New(t.Elem()).Elem()
+	default:
+		return errors.New("goform: invalid destination type")
+	}
 
-		if tag == "" || tag == "-" {
+	m := reflect.MakeMapWithSize(t, len(formValues))
+	for _, formValue := range formValues {
+		m.SetMapIndex(reflect.ValueOf(formValue).Convert(t.Key()), elem)
+	}
+
+	valf.Set(m)
+
+	return nil
+}
+
+// isEmail reports whether value is a bare email address, like
+// rick@example.com, without a display name or angle brackets.
+func isEmail(value string) bool {
+	addr, err := mail.ParseAddress(value)
+	return err == nil && addr.Address == value
+}
+
+// decodeKeyedMap binds keys like scores.1=a, or scores[1]=a, to a map,
+// decoding the map key and the value with the map's key and element types. A
+// map of structs has each struct bound from the keys below its map key, like
+// users.rick.age.
+func (d *Decoder) decodeKeyedMap(src source, tag string, valf reflect.Value, f reflect.StructField, tagOptions flags) error {
+	t := valf.Type()
+	m := reflect.MakeMap(t)
+
+	nested := d.isNested(t.Elem())
+
+	for _, mapKey := range src.subKeys(tag+".", nested) {
+		// keys of uploaded files have no values to decode
+		if !nested && len(src.values[tag+"."+mapKey]) == 0 {
 			continue
 		}
 
-		valf := val.FieldByName(f.Name)
-		kind := f.Type.Kind()
+		k := reflect.New(t.Key()).Elem()
 
-		if kind == reflect.Ptr {
-			kind = f.Type.Elem().Kind()
-			valf.Set(reflect.New(f.Type.Elem()))
-			valf = reflect.Indirect(valf)
+		err := d.decodeFormValue(k, t.Key().Kind(), reflect.StructField{}, flags{}, mapKey)
+		if err != nil {
+			return fmt.Errorf("goform: field [%s] has invalid key %q: %w", tag, mapKey, err)
 		}
 
-		formValues := r.Form[tag]
+		elem := reflect.New(t.Elem()).Elem()
 
-		if len(formValues) > 1 {
-			return errors.New("goform: arrays not supported yet")
+		if nested {
+			err = d.bindNested(src, elem, tag+"."+mapKey)
+		} else {
+			formValues := src.values[tag+"."+mapKey]
+			if len(formValues) > 1 && !d.UseFirstValue {
+				return errors.New("goform: arrays not supported yet")
+			}
+
+			err = d.decodeFormValue(elem, t.Elem().Kind(), f, tagOptions, formValues[0])
+		}
+		if err != nil {
+			return err
 		}
 
-		if len(formValues) == 0 {
-			err = decodeMultipart(r, tag, valf, kind, tagOptions)
-			if err != nil {
-				return err
-			}
+		m.SetMapIndex(k, elem)
+	}
 
-			// formValues is empty, so just move along
-			continue
+	valf.Set(m)
+
+	d.log("goform: field bound", "field", f.Name, "key", tag, "source", src.origin(tag))
+
+	return nil
+}
+
+// decodeIndexedSlice binds a slice of structs from keys like items.0.name, or
+// items[0][name]. Elements are ordered by index, and gaps between indexes are
+// dropped, so a huge index can't allocate a huge slice.
+func (d *Decoder) decodeIndexedSlice(src source, tag string, valf reflect.Value) error {
+	var indexes []int
+
+	for _, key := range src.subKeys(tag+".", true) {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 {
+			return fmt.Errorf("goform: field [%s] has invalid index %q", tag, key)
 		}
 
-		formValue := formValues[0]
+		indexes = append(indexes, index)
+	}
+
+	sort.Ints(indexes)
 
-		err = decodeFormValue(valf, kind, f, formValue)
+	slice := reflect.MakeSlice(valf.Type(), len(indexes), len(indexes))
+
+	for i, index := range indexes {
+		err := d.bindNested(src, slice.Index(i), tag+"."+strconv.Itoa(index))
 		if err != nil {
 			return err
 		}
-
 	}
 
+	valf.Set(slice)
+
 	return nil
 }
 
-func decodeFormValue(valf reflect.Value, kind reflect.Kind, f reflect.StructField, formValue string) error {
-	var err error
+// isJSONNumber reports whether value is a valid JSON number literal.
+func isJSONNumber(value string) bool {
+	if value == "" {
+		return false
+	}
 
-	switch kind {
-	case reflect.Slice:
-		if valf.Type() == reflect.TypeOf([]byte{}) {
-			valf.SetBytes([]byte(formValue))
-		}
-		break
-	case reflect.String:
-		valf.SetString(formValue)
-	case reflect.Bool:
-		err = decodeBool(valf, formValue)
-	case reflect.Int:
-		err = decodeInt(valf, f.Tag, 0, formValue)
-	case reflect.Int8:
-		err = decodeInt(valf, f.Tag, 8, formValue)
-	case reflect.Int16:
-		err = decodeInt(valf, f.Tag, 16, formValue)
-	case reflect.Int32:
-		err = decodeInt(valf, f.Tag, 32, formValue)
-	case reflect.Int64:
-		err = decodeInt(valf, f.Tag, 64, formValue)
-	case reflect.Uint:
-		err = decodeUint(valf, f.Tag, 0, formValue)
-	case reflect.Uint8:
-		err = decodeUint(valf, f.Tag, 8, formValue)
-	case reflect.Uint16:
-		err = decodeUint(valf, f.Tag, 16, formValue)
-	case reflect.Uint32:
-		err = decodeUint(valf, f.Tag, 32, formValue)
-	case reflect.Uint64:
-		err = decodeUint(valf, f.Tag, 64, formValue)
-	case reflect.Float32:
-		err = decodeFloat(valf, 32, formValue)
-	case reflect.Float64:
-		err = decodeFloat(valf, 64, formValue)
-	case reflect.Struct:
-		err = decodeStruct(valf, f, formValue)
-	default:
-		err = errors.New("goform: invalid destination type")
+	if c := value[0]; c != '-' && (c < '0' || c > '9') {
+		return false
 	}
 
-	return err
+	return json.Valid([]byte(value))
 }
 
-func decodeBool(valf reflect.Value, value string) error {
-	boolVal, err := strconv.ParseBool(value)
-	if err != nil {
-		return err
+func (d *Decoder) decodeBool(valf reflect.Value, value string) error {
+	trueValues := d.TrueValues
+	if len(trueValues) == 0 {
+		trueValues = defaultTrueValues
+	}
+
+	falseValues := d.FalseValues
+	if len(falseValues) == 0 {
+		falseValues = defaultFalseValues
+	}
+
+	switch {
+	case containsFold(trueValues, value):
+		valf.SetBool(true)
+	case containsFold(falseValues, value):
+		valf.SetBool(false)
+	default:
+		return fmt.Errorf("goform: invalid bool %q", value)
 	}
-	valf.SetBool(boolVal)
 
 	return nil
 }
 
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func decodeFloat(valf reflect.Value, bitSize int, value string) error {
 	floatVal, err := strconv.ParseFloat(value, bitSize)
 	if err != nil {
@@ -168,7 +1633,7 @@ func decodeInt(valf reflect.Value, tag reflect.StructTag, bitSize int, value str
 		return err
 	}
 
-	intVal, err := strconv.ParseInt(value, b, bitSize)
+	intVal, err := strconv.ParseInt(trimHexPrefix(value, b), b, bitSize)
 	if err != nil {
 		return err
 	}
@@ -183,7 +1648,7 @@ func decodeUint(valf reflect.Value, tag reflect.StructTag, bitSize int, value st
 		return err
 	}
 
-	intVal, err := strconv.ParseUint(value, b, bitSize)
+	intVal, err := strconv.ParseUint(trimHexPrefix(value, b), b, bitSize)
 	if err != nil {
 		return err
 	}
@@ -192,32 +1657,141 @@ func decodeUint(valf reflect.Value, tag reflect.StructTag, bitSize int, value st
 	return nil
 }
 
-func decodeStruct(valf reflect.Value, f reflect.StructField, formValue string) error {
+// trimHexPrefix drops the 0x prefix from hex values, which strconv only
+// accepts when guessing the base.
+func trimHexPrefix(value string, base int) string {
+	if base == 16 && len(value) > 2 && value[0] == '0' && (value[1] == 'x' || value[1] == 'X') {
+		return value[2:]
+	}
+
+	return value
+}
+
+// roundDecimal rounds a decimal value to scale places, with halves rounded
+// away from zero.
+func roundDecimal(value, scale string) (string, error) {
+	places, err := strconv.Atoi(scale)
+	if err != nil || places < 0 {
+		return "", fmt.Errorf("goform: invalid scale %q", scale)
+	}
+
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return "", fmt.Errorf("goform: invalid decimal %q", value)
+	}
+
+	return r.FloatString(places), nil
+}
+
+// decodeUnit binds a plain number, counted in the given unit, to an int64 or
+// time.Duration field as a number of nanoseconds.
+func decodeUnit(valf reflect.Value, kind reflect.Kind, unit, value string) error {
+	if kind != reflect.Int64 {
+		return errors.New("goform: unit tag requires an int64 or time.Duration field")
+	}
+
+	d, ok := units[unit]
+	if !ok {
+		return fmt.Errorf("goform: invalid unit %q", unit)
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if n > math.MaxInt64/int64(d) || n < math.MinInt64/int64(d) {
+		return &strconv.NumError{Func: "ParseInt", Num: value, Err: strconv.ErrRange}
+	}
+
+	valf.SetInt(n * int64(d))
+
+	return nil
+}
+
+// decodeTimeOfDay binds a time of day, parsed with the layout in format, to a
+// time.Duration field as the time since midnight.
+func decodeTimeOfDay(valf reflect.Value, format, value string) error {
+	t, err := time.Parse(format, value)
+	if err != nil {
+		return err
+	}
+
+	valf.SetInt(int64(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())))
+
+	return nil
+}
+
+// decodeUnixTime binds a number of seconds since the unix epoch to a
+// time.Time field, in loc or UTC.
+func decodeUnixTime(valf reflect.Value, loc *time.Location, formValue string) error {
+	sec, err := strconv.ParseInt(formValue, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	valf.Set(reflect.ValueOf(time.Unix(sec, 0).In(loc)))
+
+	return nil
+}
+
+// datetimeLocalLayout returns the layout of a value from an html
+// datetime-local input, which only has seconds when the input's step is
+// smaller than a minute.
+func datetimeLocalLayout(formValue string) string {
+	switch {
+	case len(formValue) > len("2006-01-02T15:04:05"):
+		return "2006-01-02T15:04:05.999999999"
+	case len(formValue) > len("2006-01-02T15:04"):
+		return "2006-01-02T15:04:05"
+	default:
+		return "2006-01-02T15:04"
+	}
+}
+
+func (d *Decoder) decodeStruct(valf reflect.Value, f reflect.StructField, tagOptions flags, formValue string) error {
 	if valf.Type() == reflect.TypeOf(time.Time{}) {
 		format := f.Tag.Get("format")
 		if format == "" {
 			format = time.RFC3339
 		}
 
+		loc, err := d.timeLocation(f)
+		if err != nil {
+			return err
+		}
+
+		if format == "unix" {
+			return decodeUnixTime(valf, loc, formValue)
+		}
+
+		if format == "datetime-local" {
+			format = datetimeLocalLayout(formValue)
+		}
+
 		var timeVal time.Time
-		var err error
 
-		tz := f.Tag.Get("tz")
-		if tz == "" {
+		if loc == nil {
 			timeVal, err = time.Parse(format, formValue)
 		} else {
-			var loc *time.Location
-			loc, err = time.LoadLocation(tz)
-			if err != nil {
-				return err
-			}
-
 			timeVal, err = time.ParseInLocation(format, formValue, loc)
 		}
 		if err != nil {
 			return err
 		}
+
+		if tagOptions.strict && timeVal.Format(format) != formValue {
+			return fmt.Errorf("goform: field [%s] value %q does not match format %q", tagOptions.key, formValue, format)
+		}
+
 		valf.Set(reflect.ValueOf(timeVal))
+	} else if scanner, ok := valf.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(formValue)
 	} else {
 		return errors.New("goform: invalid destination type")
 	}
@@ -225,67 +1799,215 @@ func decodeStruct(valf reflect.Value, f reflect.StructField, formValue string) e
 	return nil
 }
 
-func decodeMultipart(r *http.Request, tag string, valf reflect.Value, kind reflect.Kind, tagOptions flags) error {
-	if r.MultipartForm != nil {
-		headers := r.MultipartForm.File[tag]
-		if len(headers) == 0 {
-			if tagOptions.required {
-				return fmt.Errorf("goform: missing required field [%s]", tag)
-			}
-
-			return nil
+func (d *Decoder) decodeMultipart(src source, tag string, valf reflect.Value, kind reflect.Kind, tagOptions flags) error {
+	headers := src.files[tag]
+	if len(headers) == 0 {
+		if tagOptions.required {
+			return missingRequired(tag, src.multipart && isFileType(valf.Type()))
 		}
 
-		err := decodeMultipartFile(valf, kind, tagOptions, headers[0])
-		if err != nil {
-			return err
+		return nil
+	}
+
+	t := valf.Type()
+	if t.Kind() == reflect.Slice && t != reflect.TypeOf([]byte{}) && isFileType(t.Elem()) {
+		files := reflect.MakeSlice(t, len(headers), len(headers))
+
+		for i, hdr := range headers {
+			err := d.decodeMultipartFile(tag, files.Index(i), t.Elem().Kind(), tagOptions, hdr)
+			if err != nil {
+				return err
+			}
 		}
 
+		valf.Set(files)
 		return nil
 	}
 
-	if tagOptions.required {
-		return fmt.Errorf("goform: missing required field [%s]", tag)
+	return d.decodeMultipartFile(tag, valf, kind, tagOptions, headers[0])
+}
+
+// isFileType reports whether t can hold an uploaded file.
+func isFileType(t reflect.Type) bool {
+	if t == reflect.TypeOf([]byte{}) || t.Implements(imageType) || t == fsFileType {
+		return true
+	}
+
+	return t.Kind() == reflect.Slice && isFileType(t.Elem())
+}
+
+func missingRequired(tag string, file bool) error {
+	err := fmt.Errorf("goform: missing required field [%s]", tag)
+	if file {
+		err = fmt.Errorf("goform: missing required file [%s]", tag)
 	}
 
-	return nil
+	return &FieldError{Field: tag, Code: ErrRequired, Err: err}
 }
 
-func decodeMultipartFile(valf reflect.Value, kind reflect.Kind, tagOptions flags, hdr *multipart.FileHeader) error {
+func (d *Decoder) decodeMultipartFile(tag string, valf reflect.Value, kind reflect.Kind, tagOptions flags, hdr *multipart.FileHeader) error {
 	var rdr io.Reader
 	var err error
 
+	if tagOptions.maxSize > 0 && hdr.Size > tagOptions.maxSize {
+		return outOfRange("goform: file exceeds maxsize of %d bytes", tagOptions.maxSize)
+	}
+
 	data, err := hdr.Open()
 	if err != nil {
 		return err
 	}
+
+	// the caller reads and closes an fs.File itself
+	if valf.Type() == fsFileType {
+		valf.Set(reflect.ValueOf(&uploadedFile{File: data, hdr: hdr}))
+		return nil
+	}
+
 	defer data.Close()
 
 	rdr = data
 
 	if tagOptions.base64 {
-		rdr = base64.NewDecoder(base64.StdEncoding, rdr)
+		if d.StrictBase64 {
+			rdr, err = decodeStrictBase64(tag, rdr)
+			if err != nil {
+				return err
+			}
+		} else {
+			rdr = base64.NewDecoder(base64.StdEncoding, rdr)
+		}
+	}
+
+	gzipped := tagOptions.gzip ||
+		(d.GzipContentEncoding && strings.EqualFold(hdr.Header.Get("Content-Encoding"), "gzip"))
+
+	if gzipped {
+		zr, err := gzip.NewReader(rdr)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+
+		rdr = zr
+	}
+
+	// hdr.Size is the encoded size, so the decoded file is limited as well,
+	// keeping a small gzip bomb from expanding without bound
+	limit, limitErr := tagOptions.maxSize, "goform: file exceeds maxsize of %d bytes"
+	if limit <= 0 && gzipped {
+		limit, limitErr = d.MaxTotalUploadBytes, "goform: total upload size exceeds %d bytes"
+	}
+
+	var limited *io.LimitedReader
+	if limit > 0 {
+		limited = &io.LimitedReader{R: rdr, N: limit + 1}
+		rdr = limited
+	}
+
+	exceeded := func() bool {
+		return limited != nil && limited.N == 0
 	}
 
 	if valf.Type() == reflect.TypeOf([]byte{}) {
 		readData, err := ioutil.ReadAll(rdr)
+		if exceeded() {
+			return outOfRange(limitErr, limit)
+		}
 		if err != nil {
 			return err
 		}
 
 		valf.SetBytes(readData)
 		return nil
-	} else if valf.Type().Implements(reflect.TypeOf((*image.Image)(nil)).Elem()) {
+	} else if valf.Type().Implements(imageType) {
 		var img image.Image
+		var format string
 
-		img, _, err = image.Decode(rdr)
+		img, format, err = image.Decode(rdr)
+		if exceeded() {
+			return outOfRange(limitErr, limit)
+		}
 		if err != nil {
 			return err
 		}
 
-		valf.Set(reflect.ValueOf(img))
+		if tagOptions.formatField.IsValid() {
+			tagOptions.formatField.SetString(format)
+		}
+
+		if d.ImageHook != nil {
+			img, err = d.ImageHook(tag, img)
+			if err != nil {
+				return err
+			}
+
+			if isNilImage(img) {
+				return errors.New("goform: ImageHook returned no image")
+			}
+		}
+
+		return setImage(valf, img)
+	}
+
+	return nil
+}
+
+func (d *Decoder) decodeMultipartPrefix(src source, prefix string, valf reflect.Value, tagOptions flags) error {
+	if valf.Type() != reflect.TypeOf([][]byte{}) {
+		return errors.New("goform: fileprefix requires a [][]byte field")
+	}
+
+	var names []string
+	for name := range src.files {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		if tagOptions.required {
+			return missingRequired(prefix+"*", true)
+		}
+
 		return nil
 	}
 
+	// map iteration order is random, so sort to keep the files in a stable order
+	sort.Strings(names)
+
+	files := reflect.MakeSlice(valf.Type(), 0, len(names))
+
+	for _, name := range names {
+		for _, hdr := range src.files[name] {
+			file := reflect.New(valf.Type().Elem()).Elem()
+
+			err := d.decodeMultipartFile(name, file, reflect.Slice, tagOptions, hdr)
+			if err != nil {
+				return err
+			}
+
+			files = reflect.Append(files, file)
+		}
+	}
+
+	valf.Set(files)
+
 	return nil
 }
+
+// decodeStrictBase64 decodes all of rdr, rejecting anything that isn't
+// canonical base64 instead of decoding it leniently.
+func decodeStrictBase64(tag string, rdr io.Reader) (io.Reader, error) {
+	encoded, err := ioutil.ReadAll(rdr)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := base64.StdEncoding.Strict().DecodeString(string(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("goform: invalid base64 in field [%s]: %v", tag, err)
+	}
+
+	return bytes.NewReader(decoded), nil
+}
@@ -1,9 +1,11 @@
 package goform_test
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/rickbassham/goform"
 )
@@ -33,3 +35,50 @@ func ExampleUnmarshal() {
 		panic(err.Error())
 	}
 }
+
+func ExampleUnmarshalValues() {
+	data := url.Values{}
+	data.Set("id", "1")
+	data.Set("name", "rick")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(data, &b)
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
+func ExampleDecoder_RegisterParser() {
+	var d goform.Decoder
+
+	d.RegisterParser(func(value string) (time.Weekday, error) {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(day.String(), value) {
+				return day, nil
+			}
+		}
+
+		return 0, fmt.Errorf("invalid weekday %q", value)
+	})
+
+	type body struct {
+		Day  time.Weekday   `form:"day"`
+		Days []time.Weekday `form:"days"`
+	}
+
+	var b body
+
+	err := d.UnmarshalValues(url.Values{"day": {"monday"}, "days": {"Friday", "saturday"}}, &b)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	fmt.Println(b.Day, b.Days)
+	// Output: Monday [Friday Saturday]
+}
@@ -2,17 +2,29 @@ package goform_test
 
 import (
 	"bytes"
+	"compress/gzip"
+	"database/sql"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
+	"image/gif"
 	"image/png"
 	"io"
+	"io/fs"
+	"io/ioutil"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/textproto"
 	"net/url"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -359,3 +371,3595 @@ func TestUnmarshal_RequiredMissing(t *testing.T) {
 	err = goform.Unmarshal(r, &b)
 	assert.EqualError(t, err, "goform: missing required field [something]")
 }
+
+func TestUnmarshal_MultiPartFormFilePrefix(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormField(w, "id", "1")
+	writeFormFile(w, "file2", strings.NewReader("EFGH"))
+	writeFormFile(w, "file1", strings.NewReader("ABCD"))
+	writeFormFile(w, "other", strings.NewReader("IJKL"))
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		ID    int      `form:"id"`
+		Files [][]byte `form:"file*,fileprefix"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:    1,
+		Files: [][]byte{[]byte("ABCD"), []byte("EFGH")},
+	}, b)
+}
+
+func TestUnmarshal_RequiredFileMissing(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormField(w, "id", "1")
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		ID     int         `form:"id"`
+		Avatar image.Image `form:"avatar,required"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: missing required file [avatar]")
+}
+
+func TestUnmarshal_RequiredFileEmptyMultipart(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Avatar []byte `form:"avatar,required"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: missing required file [avatar]")
+}
+
+func TestUnmarshal_RequiredFileEmptyBody(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "multipart/form-data; boundary=abc")
+
+	type body struct {
+		Avatar image.Image `form:"avatar,required"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: missing required file [avatar]")
+}
+
+func TestUnmarshal_MultiPartFormConcreteImage(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	headshot := image.NewGray16(image.Rect(0, 0, 32, 32))
+	draw.Draw(headshot, image.Rect(8, 8, 24, 24), image.NewUniform(color.Gray16{128}), image.Point{0, 0}, draw.Over)
+
+	var imgBuf bytes.Buffer
+	png.Encode(&imgBuf, headshot) // nolint
+
+	writeFormFile(w, "headshot", &imgBuf)
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Headshot *image.RGBA `form:"headshot"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	expected := image.NewRGBA(headshot.Bounds())
+	draw.Draw(expected, expected.Bounds(), headshot, image.Point{0, 0}, draw.Src)
+
+	assert.Equal(t, body{
+		Headshot: expected,
+	}, b)
+}
+
+func TestUnmarshalValues(t *testing.T) {
+	data := url.Values{}
+	data.Set("id", "1")
+	data.Set("name", "rick")
+	data.Set("age", "39")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+		Age  int    `form:"age,required"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(data, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+		Age:  39,
+	}, b)
+}
+
+func TestUnmarshalValues_RequiredMissing(t *testing.T) {
+	type body struct {
+		Something string `form:"something,required"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{}, &b)
+	assert.EqualError(t, err, "goform: missing required field [something]")
+}
+
+func TestUnmarshal_CheckboxMap(t *testing.T) {
+	data := url.Values{}
+	data.Add("perms", "read")
+	data.Add("perms", "write")
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		Perms map[string]bool `form:"perms"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Perms: map[string]bool{"read": true, "write": true},
+	}, b)
+}
+
+func TestUnmarshal_MultipleValues(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1&id=2", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		ID int `form:"id"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: arrays not supported yet")
+}
+
+func TestDecoder_UseFirstValue(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1&id=2", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		ID int `form:"id"`
+	}
+
+	var b body
+
+	d := goform.Decoder{UseFirstValue: true}
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID: 1,
+	}, b)
+}
+
+func TestUnmarshal_JSONNumber(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?amount=12345678901234567890.25", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		Amount json.Number `form:"amount"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Amount: json.Number("12345678901234567890.25"),
+	}, b)
+}
+
+func TestUnmarshal_JSONNumberInvalid(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?amount=abc", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		Amount json.Number `form:"amount"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	assert.EqualError(t, err, `goform: invalid number "abc"`)
+}
+
+func TestUnmarshal_SkippedFieldFromJSON(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?name=abc&-=abc", strings.NewReader(`{"id": 1, "name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `json:"id" form:"-,required"`
+		Name string `json:"name" form:"-"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+	}, b)
+}
+
+func TestUnmarshal_Bool(t *testing.T) {
+	values := map[string]bool{
+		"1": true, "t": true, "TRUE": true, "True": true, "yes": true, "Yes": true, "on": true,
+		"0": false, "f": false, "FALSE": false, "False": false, "no": false, "NO": false, "off": false,
+	}
+
+	type body struct {
+		Enabled bool `form:"enabled"`
+	}
+
+	for value, expected := range values {
+		t.Run(value, func(t *testing.T) {
+			b := body{Enabled: !expected}
+
+			err := goform.UnmarshalValues(url.Values{"enabled": {value}}, &b)
+			require.NoError(t, err)
+
+			assert.Equal(t, body{
+				Enabled: expected,
+			}, b)
+		})
+	}
+}
+
+func TestUnmarshal_BoolInvalid(t *testing.T) {
+	type body struct {
+		Enabled bool `form:"enabled"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"enabled": {"maybe"}}, &b)
+	assert.EqualError(t, err, `goform: invalid bool "maybe"`)
+}
+
+func TestDecoder_BoolValues(t *testing.T) {
+	type body struct {
+		Enabled bool `form:"enabled"`
+	}
+
+	d := goform.Decoder{
+		TrueValues:  []string{"si"},
+		FalseValues: []string{"no"},
+	}
+
+	var b body
+
+	err := d.UnmarshalValues(url.Values{"enabled": {"SI"}}, &b)
+	require.NoError(t, err)
+	assert.True(t, b.Enabled)
+
+	err = d.UnmarshalValues(url.Values{"enabled": {"yes"}}, &b)
+	assert.EqualError(t, err, `goform: invalid bool "yes"`)
+}
+
+type requestUnmarshalerBody struct {
+	Method string `form:"method"`
+}
+
+func (b *requestUnmarshalerBody) UnmarshalRequest(r *http.Request) error {
+	b.Method = r.Method
+	return nil
+}
+
+func TestUnmarshal_RequestUnmarshaler(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPut, "http://test/page?method=abc", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	var b requestUnmarshalerBody
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, requestUnmarshalerBody{
+		Method: http.MethodPut,
+	}, b)
+}
+
+type rawTags []string
+
+func (t *rawTags) UnmarshalRequest(r *http.Request) error {
+	*t = r.URL.Query()["tag"]
+	return nil
+}
+
+func TestUnmarshal_RequestUnmarshalerNotStruct(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "http://test/page?tag=a&tag=b", nil)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	var tags rawTags
+
+	err = goform.Unmarshal(r, &tags)
+	require.NoError(t, err)
+
+	assert.Equal(t, rawTags{"a", "b"}, tags)
+}
+
+func TestUnmarshal_RequestUnmarshalerNilPointer(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPut, "http://test/page?method=abc", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	var b *requestUnmarshalerBody
+
+	err = goform.Unmarshal(r, b)
+	assert.EqualError(t, err, "goform: v must be a non-nil pointer to struct")
+}
+
+func TestUnmarshal_EmptyMultipartBody(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "multipart/form-data; boundary=abc")
+
+	type body struct {
+		ID     int    `form:"id"`
+		Name   string `form:"name"`
+		Avatar []byte `form:"avatar"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID: 1,
+	}, b)
+}
+
+func TestUnmarshal_EmptyMultipartBodyRequired(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "multipart/form-data; boundary=abc")
+
+	type body struct {
+		ID   int    `form:"id,required"`
+		Name string `form:"name,required"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: missing required field [name]")
+}
+
+func TestUnmarshal_MultipartMissingBoundary(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "multipart/form-data")
+
+	type body struct {
+		Name string `form:"name"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	assert.EqualError(t, err, "no multipart boundary param in Content-Type")
+}
+
+func TestUnmarshal_Unit(t *testing.T) {
+	type body struct {
+		TTL     time.Duration `form:"ttl" unit:"s"`
+		Timeout int64         `form:"timeout" unit:"ms"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"ttl": {"30"}, "timeout": {"1500"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		TTL:     30 * time.Second,
+		Timeout: int64(1500 * time.Millisecond),
+	}, b)
+}
+
+func TestUnmarshal_UnitInvalid(t *testing.T) {
+	type body struct {
+		TTL time.Duration `form:"ttl" unit:"d"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"ttl": {"30"}}, &b)
+	assert.EqualError(t, err, `goform: invalid unit "d"`)
+}
+
+func TestDecoder_MatchFieldNames(t *testing.T) {
+	type body struct {
+		ID       int
+		Name     string
+		Age      int    `form:"years"`
+		Ignored  string `form:"-"`
+		internal string
+	}
+
+	d := goform.Decoder{MatchFieldNames: true}
+
+	var b body
+
+	err := d.UnmarshalValues(url.Values{"ID": {"1"}, "Name": {"rick"}, "years": {"39"}, "Ignored": {"abc"}, "internal": {"abc"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+		Age:  39,
+	}, b)
+}
+
+func TestDecoder_SnakeCaseFieldNames(t *testing.T) {
+	type body struct {
+		UserID     int
+		FirstName  string
+		HTTPStatus int
+	}
+
+	d := goform.Decoder{MatchFieldNames: true, SnakeCaseFieldNames: true}
+
+	var b body
+
+	err := d.UnmarshalValues(url.Values{"user_id": {"1"}, "first_name": {"rick"}, "http_status": {"200"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		UserID:     1,
+		FirstName:  "rick",
+		HTTPStatus: 200,
+	}, b)
+}
+
+func TestUnmarshal_NoFieldNameMatching(t *testing.T) {
+	type body struct {
+		Name string
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"Name": {"rick"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{}, b)
+}
+
+func TestDecoder_JSONOverridesForm(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?something=abc&age=40", strings.NewReader(`{"id": 1, "name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `json:"id"`
+		Name string `json:"name" form:"something"`
+		Age  int    `json:"age" form:"age"`
+	}
+
+	d := goform.Decoder{JSONOverridesForm: true}
+
+	var b body
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+		Age:  40,
+	}, b)
+}
+
+type setterBody struct {
+	ID    int `form:"id"`
+	total int `form:"total"`
+}
+
+func (b *setterBody) SetTotal(value string) error {
+	total, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+
+	if total < 0 {
+		return errors.New("total must not be negative")
+	}
+
+	b.total = total
+	return nil
+}
+
+func TestDecoder_UseSetters(t *testing.T) {
+	d := goform.Decoder{UseSetters: true}
+
+	var b setterBody
+
+	err := d.UnmarshalValues(url.Values{"id": {"1"}, "total": {"12"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, setterBody{
+		ID:    1,
+		total: 12,
+	}, b)
+
+	err = d.UnmarshalValues(url.Values{"total": {"-1"}}, &b)
+	assert.EqualError(t, err, "total must not be negative")
+}
+
+func TestUnmarshal_UnexportedFieldSkipped(t *testing.T) {
+	var b setterBody
+
+	err := goform.UnmarshalValues(url.Values{"id": {"1"}, "total": {"12"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, setterBody{
+		ID: 1,
+	}, b)
+}
+
+func TestDecoder_QueryOnlyForBodylessMethods(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "http://test/page?id=1", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `json:"id" form:"id"`
+		Name string `json:"name"`
+	}
+
+	d := goform.Decoder{QueryOnlyForBodylessMethods: true}
+
+	var b body
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID: 1,
+	}, b)
+}
+
+func TestDecoder_QueryOnlyForBodylessMethodsPost(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `json:"id" form:"id"`
+		Name string `json:"name"`
+	}
+
+	d := goform.Decoder{QueryOnlyForBodylessMethods: true}
+
+	var b body
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+	}, b)
+}
+
+func TestUnmarshal_SpaceDelimited(t *testing.T) {
+	type body struct {
+		Scope []string `form:"scope,space"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"scope": {" read  write\tadmin "}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Scope: []string{"read", "write", "admin"},
+	}, b)
+}
+
+func TestUnmarshal_NilPointer(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `json:"name"`
+	}
+
+	err = goform.Unmarshal(r, (*body)(nil))
+	assert.EqualError(t, err, "goform: v must be a non-nil pointer to struct")
+
+	err = goform.UnmarshalValues(url.Values{"id": {"1"}}, (*body)(nil))
+	assert.EqualError(t, err, "goform: v must be a non-nil pointer to struct")
+}
+
+func TestUnmarshal_NotPointer(t *testing.T) {
+	type body struct {
+		ID int `form:"id"`
+	}
+
+	err := goform.UnmarshalValues(url.Values{"id": {"1"}}, body{})
+	assert.EqualError(t, err, "goform: v must be a pointer")
+}
+
+func TestUnmarshal_NotStruct(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`1`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	var i int
+
+	err = goform.Unmarshal(r, &i)
+	assert.EqualError(t, err, "goform: v must be a non-nil pointer to struct")
+
+	var m map[string]string
+
+	err = goform.UnmarshalValues(url.Values{"id": {"1"}}, &m)
+	assert.EqualError(t, err, "goform: v must be a non-nil pointer to struct")
+}
+
+func TestUnmarshal_Slice(t *testing.T) {
+	data := url.Values{}
+	data.Add("ids", "1")
+	data.Add("ids", "2")
+	data.Add("names", "rick")
+	data.Add("names", "bob")
+	data.Add("ages", "39")
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		IDs   []int    `form:"ids"`
+		Names []string `form:"names"`
+		Ages  []*uint8 `form:"ages"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	age := uint8(39)
+
+	assert.Equal(t, body{
+		IDs:   []int{1, 2},
+		Names: []string{"rick", "bob"},
+		Ages:  []*uint8{&age},
+	}, b)
+}
+
+func TestUnmarshal_SliceBase(t *testing.T) {
+	type body struct {
+		IDs  []int  `form:"ids" base:"16"`
+		Mask []uint `form:"mask,space" base:"2"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"ids": {"0xA", "0xB", "ff"}, "mask": {"101 11"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		IDs:  []int{10, 11, 255},
+		Mask: []uint{5, 3},
+	}, b)
+}
+
+func TestUnmarshal_SpaceDelimitedNotSlice(t *testing.T) {
+	type body struct {
+		Scope string `form:"scope,space"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"scope": {"read write"}}, &b)
+	assert.EqualError(t, err, "goform: space option requires a slice field")
+}
+
+func TestDecoder_Logger(t *testing.T) {
+	data := url.Values{}
+	data.Set("name", "rick")
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+		Age  int    `form:"age"`
+	}
+
+	var events []string
+
+	d := goform.Decoder{
+		Logger: func(msg string, kv ...interface{}) {
+			events = append(events, fmt.Sprint(append([]interface{}{msg}, kv...)...))
+		},
+	}
+
+	var b body
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		fmt.Sprint("goform: field bound", "field", "ID", "key", "id", "source", "query", "value", []string{"1"}),
+		fmt.Sprint("goform: field bound", "field", "Name", "key", "name", "source", "form", "value", []string{"rick"}),
+		fmt.Sprint("goform: field not present", "field", "Age", "key", "age"),
+	}, events)
+}
+
+func TestDecoder_RegisterParserInvalid(t *testing.T) {
+	var d goform.Decoder
+
+	assert.PanicsWithValue(t, "goform: RegisterParser expects a func(string) (T, error)", func() {
+		d.RegisterParser(func(value string) int { return 0 })
+	})
+}
+
+func TestDecoder_RegisterParserError(t *testing.T) {
+	var d goform.Decoder
+
+	d.RegisterParser(func(value string) (time.Weekday, error) {
+		return 0, errors.New("invalid weekday")
+	})
+
+	type body struct {
+		Day time.Weekday `form:"day"`
+	}
+
+	var b body
+
+	err := d.UnmarshalValues(url.Values{"day": {"someday"}}, &b)
+	assert.EqualError(t, err, "invalid weekday")
+}
+
+func TestDecoder_StrictBase64(t *testing.T) {
+	newRequest := func() *http.Request {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+
+		// the final B has bits set past the end of the data
+		writeFormFile(w, "data", strings.NewReader("QUJDRB=="))
+
+		w.Close() // nolint
+
+		r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+		require.NoError(t, err)
+		require.NotNil(t, r)
+
+		r.Header.Add("Content-Type", w.FormDataContentType())
+
+		return r
+	}
+
+	type body struct {
+		Data []byte `form:"data,base64"`
+	}
+
+	var b body
+
+	err := goform.Unmarshal(newRequest(), &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Data: []byte("ABCD"),
+	}, b)
+
+	d := goform.Decoder{StrictBase64: true}
+
+	err = d.Unmarshal(newRequest(), &b)
+	assert.EqualError(t, err, "goform: invalid base64 in field [data]: illegal base64 data at input byte 6")
+}
+
+type decimal struct {
+	value string
+}
+
+func (d *decimal) UnmarshalText(text []byte) error {
+	d.value = string(text)
+	return nil
+}
+
+func TestUnmarshal_TextUnmarshaler(t *testing.T) {
+	type body struct {
+		IP    net.IP   `form:"ip"`
+		Price decimal  `form:"price"`
+		Total *decimal `form:"total"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"ip": {"10.0.0.1"}, "price": {"1.005"}, "total": {"12"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		IP:    net.ParseIP("10.0.0.1"),
+		Price: decimal{"1.005"},
+		Total: &decimal{"12"},
+	}, b)
+}
+
+func TestUnmarshal_TextUnmarshalerSlice(t *testing.T) {
+	type body struct {
+		IPs    []net.IP   `form:"ip"`
+		Prices []decimal  `form:"price"`
+		Totals []*decimal `form:"total" sep:","`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"ip": {"10.0.0.1", "::1"}, "price": {"1.005", "2"}, "total": {"12,13"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		IPs:    []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("::1")},
+		Prices: []decimal{{"1.005"}, {"2"}},
+		Totals: []*decimal{{"12"}, {"13"}},
+	}, b)
+
+	err = goform.UnmarshalValues(url.Values{"ip": {"10.0.0.1", "bad"}}, &b)
+	assert.EqualError(t, err, "invalid IP address: bad (field [ip], element 1)")
+}
+
+func TestUnmarshal_Scale(t *testing.T) {
+	type body struct {
+		Price  decimal `form:"price" scale:"2"`
+		Amount float64 `form:"amount" scale:"1"`
+		Debit  decimal `form:"debit" scale:"0"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"price": {"1.005"}, "amount": {"2.25"}, "debit": {"-2.5"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Price:  decimal{"1.01"},
+		Amount: 2.3,
+		Debit:  decimal{"-3"},
+	}, b)
+}
+
+func TestUnmarshal_ScaleInvalid(t *testing.T) {
+	type body struct {
+		Price decimal `form:"price" scale:"2"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"price": {"abc"}}, &b)
+	assert.EqualError(t, err, `goform: invalid decimal "abc"`)
+}
+
+func TestUnmarshal_AllValues(t *testing.T) {
+	data := url.Values{}
+	data.Add("name", "rick")
+	data.Add("tags", "a")
+	data.Add("tags", "b")
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		ID     int                 `form:"id"`
+		All    map[string][]string `form:"*"`
+		Values url.Values          `form:"*"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	all := map[string][]string{
+		"id":   {"1"},
+		"name": {"rick"},
+		"tags": {"a", "b"},
+	}
+
+	assert.Equal(t, body{
+		ID:     1,
+		All:    all,
+		Values: url.Values(all),
+	}, b)
+}
+
+func TestUnmarshal_AllValuesPrefixed(t *testing.T) {
+	type extra struct {
+		All url.Values `form:"*"`
+	}
+
+	type body struct {
+		Name  string     `form:"name"`
+		Extra extra      `form:"in"`
+		All   url.Values `form:"*"`
+	}
+
+	r, err := http.NewRequest(http.MethodGet, "http://test/page?shipping_name=rick&shipping_in.a=1&shipping_in.b=2&billing_name=bob", nil)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	var b body
+
+	err = goform.UnmarshalPrefixed(r, &b, "shipping_")
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Name:  "rick",
+		Extra: extra{All: url.Values{"a": {"1"}, "b": {"2"}}},
+		All:   url.Values{"name": {"rick"}, "in.a": {"1"}, "in.b": {"2"}},
+	}, b)
+}
+
+func TestUnmarshal_AllValuesInvalid(t *testing.T) {
+	type body struct {
+		All map[string]string `form:"*"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"id": {"1"}}, &b)
+	assert.EqualError(t, err, "goform: * requires a map[string][]string field")
+}
+
+func newChunkedRequest(t *testing.T, target, body string) *http.Request {
+	r, err := http.NewRequest(http.MethodPost, target, ioutil.NopCloser(strings.NewReader(body)))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.ContentLength = -1
+	r.TransferEncoding = []string{"chunked"}
+
+	return r
+}
+
+func TestUnmarshal_ChunkedJSON(t *testing.T) {
+	r := newChunkedRequest(t, "http://test/page?something=abc", `{"id": 1, "name": "rick"}`)
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID        int    `json:"id"`
+		Name      string `json:"name"`
+		Something string `form:"something"`
+	}
+
+	var b body
+
+	err := goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:        1,
+		Name:      "rick",
+		Something: "abc",
+	}, b)
+}
+
+func TestUnmarshal_ChunkedJSONOverridesForm(t *testing.T) {
+	r := newChunkedRequest(t, "http://test/page?something=abc", `{"id": 1, "name": "rick"}`)
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID        int    `json:"id"`
+		Name      string `json:"name"`
+		Something string `form:"something"`
+	}
+
+	d := goform.Decoder{JSONOverridesForm: true}
+
+	var b body
+
+	err := d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:        1,
+		Name:      "rick",
+		Something: "abc",
+	}, b)
+}
+
+func TestUnmarshal_ChunkedURLEncoded(t *testing.T) {
+	r := newChunkedRequest(t, "http://test/page?id=1", "name=rick&age=39")
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+		Age  int    `form:"age"`
+	}
+
+	var b body
+
+	err := goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+		Age:  39,
+	}, b)
+}
+
+func TestUnmarshal_JSONLeavesFormUnparsed(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?something=abc", strings.NewReader(`{"id": 1}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID        int    `json:"id"`
+		Something string `form:"something"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:        1,
+		Something: "abc",
+	}, b)
+
+	assert.Nil(t, r.Form)
+	assert.Nil(t, r.MultipartForm)
+}
+
+func TestUnmarshal_QueryStringAndJSONAfterBodyRead(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?something=abc&tags=a&tags=b", strings.NewReader(`{"id": 1, "name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	// simulate middleware that parsed the form before the body was available
+	r.Form = url.Values{"something": {"stale"}}
+
+	type body struct {
+		ID        int      `json:"id"`
+		Name      string   `json:"name"`
+		Something string   `form:"something"`
+		Tags      []string `form:"tags"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:        1,
+		Name:      "rick",
+		Something: "abc",
+		Tags:      []string{"a", "b"},
+	}, b)
+}
+
+func TestUnmarshal_Time(t *testing.T) {
+	type body struct {
+		Created time.Time `form:"created"`
+		Day     time.Time `form:"day" format:"2006-01-02" tz:"America/Chicago"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"created": {"2020-01-02T15:04:05Z"}, "day": {"2020-01-02"}}, &b)
+	require.NoError(t, err)
+
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Created: time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC),
+		Day:     time.Date(2020, 1, 2, 0, 0, 0, 0, chicago),
+	}, b)
+}
+
+func TestUnmarshal_TimeStrict(t *testing.T) {
+	type body struct {
+		Day time.Time `form:"day,strict" format:"2006-1-2"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"day": {"2020-1-2"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Day: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+	}, b)
+
+	err = goform.UnmarshalValues(url.Values{"day": {"2020-01-02"}}, &b)
+	assert.EqualError(t, err, `goform: field [day] value "2020-01-02" does not match format "2006-1-2"`)
+}
+
+func TestUnmarshal_MultiPartFormImageSlice(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	first := image.NewGray16(image.Rect(0, 0, 32, 32))
+	draw.Draw(first, image.Rect(8, 8, 24, 24), image.NewUniform(color.Gray16{128}), image.Point{0, 0}, draw.Over)
+
+	second := image.NewGray16(image.Rect(0, 0, 16, 16))
+	draw.Draw(second, image.Rect(4, 4, 12, 12), image.NewUniform(color.Gray16{64}), image.Point{0, 0}, draw.Over)
+
+	for _, img := range []image.Image{first, second} {
+		var imgBuf bytes.Buffer
+		bw := base64.NewEncoder(base64.StdEncoding, &imgBuf)
+		png.Encode(bw, img) // nolint
+		bw.Close()          // nolint
+
+		writeFormFile(w, "gallery", &imgBuf)
+	}
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Gallery []image.Image `form:"gallery,base64,required"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Gallery: []image.Image{first, second},
+	}, b)
+}
+
+func TestDecoder_MaxTotalUploadBytes(t *testing.T) {
+	newRequest := func() *http.Request {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+
+		writeFormFile(w, "file1", strings.NewReader("ABCD"))
+		writeFormFile(w, "file2", strings.NewReader("EFGH"))
+
+		w.Close() // nolint
+
+		r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+		require.NoError(t, err)
+		require.NotNil(t, r)
+
+		r.Header.Add("Content-Type", w.FormDataContentType())
+
+		return r
+	}
+
+	type body struct {
+		Files [][]byte `form:"file*,fileprefix"`
+	}
+
+	var b body
+
+	d := goform.Decoder{MaxTotalUploadBytes: 8}
+
+	err := d.Unmarshal(newRequest(), &b)
+	require.NoError(t, err)
+
+	d.MaxTotalUploadBytes = 7
+
+	err = d.Unmarshal(newRequest(), &b)
+	assert.EqualError(t, err, "goform: total upload size exceeds 7 bytes")
+}
+
+func TestDecoder_MaxTotalUploadBytesStopsReading(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormFile(w, "file1", bytes.NewReader(make([]byte, 4<<20)))
+
+	w.Close() // nolint
+
+	size := int64(buf.Len())
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		File []byte `form:"file1"`
+	}
+
+	var b body
+
+	d := goform.Decoder{MaxTotalUploadBytes: 8}
+
+	result, err := d.UnmarshalWithResult(r, &b)
+	assert.EqualError(t, err, "goform: total upload size exceeds 8 bytes")
+	assert.Less(t, result.BytesRead, size)
+	assert.Nil(t, b.File)
+}
+
+func TestUnmarshal_ErrMsg(t *testing.T) {
+	type body struct {
+		Name string `form:"name,required" errmsg:"Please provide your name"`
+		Age  int    `form:"age" errmsg:"Please provide a valid age"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"age": {"39"}}, &b)
+	assert.EqualError(t, err, "Please provide your name")
+
+	err = goform.UnmarshalValues(url.Values{"name": {"rick"}, "age": {"old"}}, &b)
+	assert.EqualError(t, err, "Please provide a valid age")
+}
+
+type defaultsBody struct {
+	Page    int    `json:"page" form:"page"`
+	PerPage int    `json:"per_page" form:"per_page"`
+	Sort    string `json:"sort" form:"sort"`
+}
+
+func (b *defaultsBody) Defaults() {
+	b.Page = 1
+	b.PerPage = 20
+	b.Sort = "name"
+}
+
+func TestUnmarshal_Defaulter(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?page=3", strings.NewReader(`{"sort": "age"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	var b defaultsBody
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultsBody{
+		Page:    3,
+		PerPage: 20,
+		Sort:    "age",
+	}, b)
+
+	err = goform.UnmarshalValues(url.Values{"per_page": {"50"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultsBody{
+		Page:    1,
+		PerPage: 50,
+		Sort:    "name",
+	}, b)
+}
+
+func TestUnmarshal_QueryStringOtherContentType(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1&name=rick", strings.NewReader("a,b,c\n1,2,3\n"))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "text/csv")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+	}, b)
+
+	data, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "a,b,c\n1,2,3\n", string(data))
+}
+
+func TestUnmarshal_QueryStringNoContentType(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "http://test/page?id=1&name=rick", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+	}, b)
+}
+
+func TestUnmarshal_NilBody(t *testing.T) {
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name" json:"name"`
+	}
+
+	for _, contentType := range []string{"", "application/json", "application/x-www-form-urlencoded", "multipart/form-data; boundary=x"} {
+		r, err := http.NewRequest(http.MethodGet, "http://test/page?id=1", nil)
+		require.NoError(t, err)
+		require.NotNil(t, r)
+
+		if contentType != "" {
+			r.Header.Add("Content-Type", contentType)
+		}
+
+		var b body
+
+		d := goform.Decoder{BindQueryOnBodyError: true, BufferBody: true, DisallowBodyForEmptyStruct: true}
+
+		assert.NotPanics(t, func() {
+			err = d.Unmarshal(r, &b)
+		}, contentType)
+
+		if contentType == "" || contentType == "application/x-www-form-urlencoded" {
+			require.NoError(t, err, contentType)
+		}
+
+		assert.Equal(t, body{ID: 1}, b, contentType)
+	}
+}
+
+func TestUnmarshal_MultiPartFormAndQueryStringSlices(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormField(w, "tags", "c")
+	writeFormField(w, "ids", "3")
+	writeFormFile(w, "files", strings.NewReader("ABCD"))
+	writeFormFile(w, "files", strings.NewReader("EFGH"))
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?tags=a&tags=b&ids=1", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Tags  []string `form:"tags"`
+		IDs   []int    `form:"ids"`
+		Files [][]byte `form:"files"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Tags:  []string{"a", "b", "c"},
+		IDs:   []int{1, 3},
+		Files: [][]byte{[]byte("ABCD"), []byte("EFGH")},
+	}, b)
+}
+
+func TestDecoder_MaxContentLength(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader("name=rick&age=39"))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		Name string `form:"name"`
+		Age  int    `form:"age"`
+	}
+
+	d := goform.Decoder{MaxContentLength: 10}
+
+	var b body
+
+	err = d.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: content length exceeds 10 bytes")
+	assert.Nil(t, r.Form)
+}
+
+func TestUnmarshal_BoolSlice(t *testing.T) {
+	type body struct {
+		Flags []bool `form:"flags"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"flags": {"on", "off", "Yes", "0", "TRUE"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Flags: []bool{true, false, true, false, true},
+	}, b)
+
+	err = goform.UnmarshalValues(url.Values{"flags": {"on", "maybe"}}, &b)
+	assert.EqualError(t, err, `goform: invalid bool "maybe" (field [flags], element 1)`)
+
+	d := goform.Decoder{TrueValues: []string{"x"}, FalseValues: []string{""}}
+
+	err = d.UnmarshalValues(url.Values{"flags": {"x", "", "X"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Flags: []bool{true, false, true},
+	}, b)
+}
+
+func TestUnmarshal_Methods(t *testing.T) {
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name,required" methods:"POST, PUT"`
+	}
+
+	r, err := http.NewRequest(http.MethodGet, "http://test/page?id=1&name=rick", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID: 1,
+	}, b)
+
+	r, err = http.NewRequest(http.MethodPut, "http://test/page?id=1&name=rick", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+	}, b)
+}
+
+func TestUnmarshal_MultiPartFormGzip(t *testing.T) {
+	gzipped := func(data string) *bytes.Buffer {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(data)) // nolint
+		zw.Close()             // nolint
+		return &buf
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	hdr := textproto.MIMEHeader{}
+	hdr.Set("Content-Disposition", `form-data; name="notes"; filename="notes.txt"`)
+	hdr.Set("Content-Encoding", "gzip")
+	pw, _ := w.CreatePart(hdr)
+	io.Copy(pw, gzipped("some notes")) // nolint
+
+	writeFormFile(w, "log", gzipped("some logs"))
+
+	var encoded bytes.Buffer
+	bw := base64.NewEncoder(base64.StdEncoding, &encoded)
+	io.Copy(bw, gzipped("some data")) // nolint
+	bw.Close()                        // nolint
+
+	writeFormFile(w, "data", &encoded)
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Notes []byte `form:"notes"`
+		Log   []byte `form:"log,gzip"`
+		Data  []byte `form:"data,base64,gzip"`
+	}
+
+	var b body
+
+	d := goform.Decoder{GzipContentEncoding: true}
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Notes: []byte("some notes"),
+		Log:   []byte("some logs"),
+		Data:  []byte("some data"),
+	}, b)
+}
+
+func TestUnmarshal_MultiPartFormGzipHeader(t *testing.T) {
+	var zipped bytes.Buffer
+	zw := gzip.NewWriter(&zipped)
+	zw.Write(bytes.Repeat([]byte("a"), 1000)) // nolint
+	zw.Close()                                // nolint
+
+	newRequest := func() *http.Request {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+
+		hdr := textproto.MIMEHeader{}
+		hdr.Set("Content-Disposition", `form-data; name="notes"; filename="notes.txt"`)
+		hdr.Set("Content-Encoding", "gzip")
+		pw, _ := w.CreatePart(hdr)
+		pw.Write(zipped.Bytes()) // nolint
+
+		w.Close() // nolint
+
+		r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+		require.NoError(t, err)
+		require.NotNil(t, r)
+
+		r.Header.Add("Content-Type", w.FormDataContentType())
+
+		return r
+	}
+
+	type body struct {
+		Notes []byte `form:"notes"`
+	}
+
+	// without GzipContentEncoding the header is ignored
+	var b body
+
+	err := goform.Unmarshal(newRequest(), &b)
+	require.NoError(t, err)
+	assert.Equal(t, zipped.Bytes(), b.Notes)
+
+	// the decoded file is limited by MaxTotalUploadBytes
+	d := goform.Decoder{GzipContentEncoding: true, MaxTotalUploadBytes: 500}
+
+	err = d.Unmarshal(newRequest(), &b)
+	require.Error(t, err)
+	assert.EqualError(t, err, "goform: total upload size exceeds 500 bytes")
+}
+
+func TestUnmarshal_MaxSize(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormField(w, "note", "short")
+	writeFormFile(w, "data", strings.NewReader("ABCD"))
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Note []byte `form:"note" maxsize:"5"`
+		Data []byte `form:"data" maxsize:"4"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Note: []byte("short"),
+		Data: []byte("ABCD"),
+	}, b)
+}
+
+func TestUnmarshal_MaxSizeExceeded(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormField(w, "note", "too long")
+	writeFormFile(w, "data", strings.NewReader("ABCDE"))
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Note []byte `form:"note" maxsize:"5"`
+		Data []byte `form:"data" maxsize:"4"`
+	}
+
+	var b body
+
+	d := goform.Decoder{CollectErrors: true}
+
+	err = d.Unmarshal(r, &b)
+	require.Error(t, err)
+
+	var multiErr goform.MultiError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr, 2)
+	assert.Equal(t, "note", multiErr[0].Field)
+	assert.Equal(t, goform.ErrOutOfRange, multiErr[0].Code)
+	assert.Equal(t, "data", multiErr[1].Field)
+	assert.Equal(t, goform.ErrOutOfRange, multiErr[1].Code)
+}
+
+func TestUnmarshal_MaxSizeGzip(t *testing.T) {
+	var zipped bytes.Buffer
+	zw := gzip.NewWriter(&zipped)
+	zw.Write(make([]byte, 1<<20)) // nolint
+	zw.Close()                    // nolint
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormFile(w, "data", &zipped)
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Data []byte `form:"data,gzip" maxsize:"4096"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: file exceeds maxsize of 4096 bytes")
+	assert.True(t, errors.Is(err, goform.ErrOutOfRange))
+	assert.Nil(t, b.Data)
+}
+
+func TestUnmarshal_ImageFormatField(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	headshot := image.NewGray16(image.Rect(0, 0, 32, 32))
+
+	var imgBuf bytes.Buffer
+	png.Encode(&imgBuf, headshot) // nolint
+
+	writeFormFile(w, "headshot", &imgBuf)
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Headshot       image.Image `form:"headshot" formatfield:"HeadshotFormat"`
+		HeadshotFormat string
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Headshot:       headshot,
+		HeadshotFormat: "png",
+	}, b)
+}
+
+func TestUnmarshal_Separator(t *testing.T) {
+	type body struct {
+		IDs   []int    `form:"ids" sep:"|"`
+		Names []string `form:"names" sep:";"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"ids": {"1|2||3"}, "names": {"rick;;bob;", "jim"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		IDs:   []int{1, 2, 3},
+		Names: []string{"rick", "bob", "jim"},
+	}, b)
+}
+
+func TestUnmarshal_SeparatorNotSlice(t *testing.T) {
+	type body struct {
+		IDs string `form:"ids" sep:"|"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"ids": {"1|2"}}, &b)
+	assert.EqualError(t, err, "goform: sep tag requires a slice field")
+}
+
+func TestUnmarshal_CSVNotSlice(t *testing.T) {
+	type body struct {
+		IDs string `form:"ids,csv"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"ids": {"1,2"}}, &b)
+	assert.EqualError(t, err, "goform: csv option requires a slice field")
+}
+
+func TestUnmarshal_RequiredFromJSON(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(`{"NAME": "rick", "years": 39}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `form:"id,required"`
+		Name string `form:"name,required"`
+		Age  int    `form:"age,required" json:"years"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+		Age:  39,
+	}, b)
+}
+
+func TestUnmarshal_RequiredFromJSONOverridesForm(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		Name string `form:"name,required"`
+		Age  int    `form:"age,required"`
+	}
+
+	var b body
+
+	d := goform.Decoder{JSONOverridesForm: true}
+
+	err = d.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: missing required field [age]")
+}
+
+func TestUnmarshal_PointerToSlice(t *testing.T) {
+	type body struct {
+		Tags  *[]string `form:"tag"`
+		IDs   *[]int    `form:"ids" sep:","`
+		Other *[]string `form:"other"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"tag": {"a", "b"}, "ids": {"1,2"}}, &b)
+	require.NoError(t, err)
+
+	require.NotNil(t, b.Tags)
+	assert.Equal(t, []string{"a", "b"}, *b.Tags)
+	require.NotNil(t, b.IDs)
+	assert.Equal(t, []int{1, 2}, *b.IDs)
+	assert.Nil(t, b.Other)
+}
+
+func TestUnmarshal_PointerKeyed(t *testing.T) {
+	type item struct {
+		Name string `form:"name"`
+	}
+
+	type body struct {
+		Scores *map[int]string `form:"scores"`
+		Items  *[]item         `form:"items"`
+		Other  *map[int]string `form:"other"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"scores[1]": {"a"}, "items[0][name]": {"x"}}, &b)
+	require.NoError(t, err)
+
+	require.NotNil(t, b.Scores)
+	assert.Equal(t, map[int]string{1: "a"}, *b.Scores)
+	require.NotNil(t, b.Items)
+	assert.Equal(t, []item{{Name: "x"}}, *b.Items)
+	assert.Nil(t, b.Other)
+}
+
+func TestUnmarshal_MultiPartFormImageGIF(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	headshot := image.NewPaletted(image.Rect(0, 0, 32, 32), color.Palette{color.Black, color.White})
+
+	var imgBuf bytes.Buffer
+	gif.Encode(&imgBuf, headshot, nil) // nolint
+
+	writeFormFile(w, "headshot", &imgBuf)
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Headshot       image.Image `form:"headshot" formatfield:"HeadshotFormat"`
+		HeadshotFormat string
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, "gif", b.HeadshotFormat)
+	assert.Equal(t, headshot.Bounds(), b.Headshot.Bounds())
+}
+
+func TestUnmarshal_FormatFieldUnexported(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	var imgBuf bytes.Buffer
+	png.Encode(&imgBuf, image.NewRGBA(image.Rect(0, 0, 4, 4))) // nolint
+
+	writeFormFile(w, "img", &imgBuf)
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Img       image.Image `form:"img" formatfield:"imgFormat"`
+		imgFormat string
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	assert.EqualError(t, err, `goform: formatfield "imgFormat" must name an exported field`)
+	assert.Empty(t, b.imgFormat)
+}
+
+func TestDecoder_UseTimeZoneHeader(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	type body struct {
+		Start time.Time `form:"start" format:"2006-01-02 15:04"`
+		End   time.Time `form:"end" format:"2006-01-02 15:04" tz:"UTC"`
+	}
+
+	tests := []struct {
+		name     string
+		timeZone string
+		loc      *time.Location
+	}{
+		{name: "header", timeZone: "America/Chicago", loc: chicago},
+		{name: "absent", loc: time.UTC},
+		{name: "invalid", timeZone: "Not/AZone", loc: time.UTC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, "http://test/page?start=2020-01-02+15:04&end=2020-01-02+15:04", strings.NewReader(""))
+			require.NoError(t, err)
+			require.NotNil(t, r)
+
+			if tt.timeZone != "" {
+				r.Header.Set("Time-Zone", tt.timeZone)
+			}
+
+			var b body
+
+			d := goform.Decoder{UseTimeZoneHeader: true}
+
+			err = d.Unmarshal(r, &b)
+			require.NoError(t, err)
+
+			assert.True(t, time.Date(2020, 1, 2, 15, 4, 0, 0, tt.loc).Equal(b.Start))
+			assert.Equal(t, tt.loc, b.Start.Location())
+			assert.Equal(t, time.UTC, b.End.Location())
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1&age=old", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name,required"`
+		Age  int    `form:"age"`
+	}
+
+	b := body{Name: "rick"}
+
+	d := goform.Decoder{CollectErrors: true}
+
+	err = d.Validate(r, &b)
+	require.Error(t, err)
+
+	var multiErr goform.MultiError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr, 2)
+	assert.Equal(t, "name", multiErr[0].Field)
+	assert.Equal(t, goform.ErrRequired, multiErr[0].Code)
+	assert.Equal(t, "age", multiErr[1].Field)
+	assert.Equal(t, goform.ErrParse, multiErr[1].Code)
+
+	assert.Equal(t, body{Name: "rick"}, b)
+}
+
+func TestValidate_Valid(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1&name=rick", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name,required"`
+	}
+
+	var b body
+
+	err = goform.Validate(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{}, b)
+}
+
+func TestUnmarshal_ByteArray(t *testing.T) {
+	type body struct {
+		Digest    [4]byte `form:"digest"`
+		Signature [4]byte `form:"signature,base64"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"digest": {"deadbeef"}, "signature": {"AQIDBA=="}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Digest:    [4]byte{0xde, 0xad, 0xbe, 0xef},
+		Signature: [4]byte{1, 2, 3, 4},
+	}, b)
+}
+
+func TestUnmarshal_ByteArrayWrongLength(t *testing.T) {
+	type body struct {
+		Digest [4]byte `form:"digest"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"digest": {"deadbe"}}, &b)
+	assert.EqualError(t, err, "goform: value has 3 bytes, expected 4")
+}
+
+func TestDecoder_AllowedFields(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPatch, "http://test/page?id=2&name=bob", strings.NewReader(`{"age": 40, "admin": true}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID    int    `form:"id"`
+		Name  string `form:"name"`
+		Age   int    `json:"age"`
+		Admin bool   `json:"admin"`
+	}
+
+	b := body{ID: 1, Name: "rick", Age: 39}
+
+	d := goform.Decoder{AllowedFields: []string{"name", "age"}}
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "bob",
+		Age:  40,
+	}, b)
+}
+
+func TestDecoder_StrictAllowedFields(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPatch, "http://test/page?id=2&name=bob", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+	}
+
+	var b body
+
+	d := goform.Decoder{AllowedFields: []string{"name"}, StrictAllowedFields: true}
+
+	err = d.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: field [id] is not allowed")
+}
+
+func TestDecoder_StrictAllowedFieldsJSON(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPatch, "http://test/page", strings.NewReader(`{"name": "bob", "admin": true}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		Name  string `json:"name"`
+		Admin bool   `json:"admin"`
+	}
+
+	var b body
+
+	d := goform.Decoder{AllowedFields: []string{"name"}, StrictAllowedFields: true}
+
+	err = d.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: field [admin] is not allowed")
+}
+
+func TestDecoder_BlockedFields(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=2&name=bob&is_admin=true", strings.NewReader(`{"age": 40, "owner": "bob"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID      int    `form:"id,readonly"`
+		Name    string `form:"name"`
+		IsAdmin bool   `form:"is_admin"`
+		Age     int    `json:"age"`
+		Owner   string `json:"owner"`
+	}
+
+	b := body{ID: 1, Name: "rick", Owner: "rick"}
+
+	d := goform.Decoder{BlockedFields: []string{"is_admin", "owner"}}
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:    1,
+		Name:  "bob",
+		Age:   40,
+		Owner: "rick",
+	}, b)
+}
+
+func TestUnmarshal_DatetimeLocal(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	type body struct {
+		Start time.Time `form:"start" format:"datetime-local"`
+		End   time.Time `form:"end" format:"datetime-local" tz:"America/Chicago"`
+	}
+
+	var b body
+
+	err = goform.UnmarshalValues(url.Values{"start": {"2020-01-02T15:04"}, "end": {"2020-01-02T15:04:05"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Start: time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC),
+		End:   time.Date(2020, 1, 2, 15, 4, 5, 0, chicago),
+	}, b)
+}
+
+func TestUnmarshal_Checkbox(t *testing.T) {
+	type body struct {
+		Subscribe bool `form:"subscribe,checkbox,required"`
+		Terms     bool `form:"terms,checkbox"`
+	}
+
+	b := body{Subscribe: true, Terms: true}
+
+	err := goform.UnmarshalValues(url.Values{"terms": {"on"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Subscribe: false,
+		Terms:     true,
+	}, b)
+}
+
+func TestUnmarshal_CheckboxNotBool(t *testing.T) {
+	type body struct {
+		Subscribe string `form:"subscribe,checkbox"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"subscribe": {"on"}}, &b)
+	assert.EqualError(t, err, "goform: checkbox option requires a bool field")
+}
+
+func TestUnmarshal_MultiPartFormJSONField(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	headshot := image.NewGray16(image.Rect(0, 0, 32, 32))
+
+	var imgBuf bytes.Buffer
+	png.Encode(&imgBuf, headshot) // nolint
+
+	writeFormField(w, "profile", `{"name": "rick", "age": 39}`)
+	writeFormField(w, "tags", `{"role": "admin"}`)
+	writeFormFile(w, "headshot", &imgBuf)
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type profile struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	type body struct {
+		Profile  profile           `form:"profile,json"`
+		Tags     map[string]string `form:"tags,json"`
+		Headshot image.Image       `form:"headshot"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Profile:  profile{Name: "rick", Age: 39},
+		Tags:     map[string]string{"role": "admin"},
+		Headshot: headshot,
+	}, b)
+}
+
+func TestUnmarshal_Uintptr(t *testing.T) {
+	type body struct {
+		Addr uintptr `form:"addr"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"addr": {"1234"}}, &b)
+	assert.EqualError(t, err, "goform: field Addr is a uintptr, which holds a memory address and can't be bound")
+}
+
+func TestUnmarshal_Set(t *testing.T) {
+	type body struct {
+		Roles map[string]struct{} `form:"roles"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"roles": {"a", "a", "b"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Roles: map[string]struct{}{"a": {}, "b": {}},
+	}, b)
+}
+
+func TestDecoder_ImageHook(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	headshot := image.NewGray16(image.Rect(0, 0, 32, 32))
+
+	var imgBuf bytes.Buffer
+	png.Encode(&imgBuf, headshot) // nolint
+
+	writeFormFile(w, "headshot", bytes.NewReader(imgBuf.Bytes()))
+	writeFormFile(w, "banner", bytes.NewReader(imgBuf.Bytes()))
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Headshot image.Image `form:"headshot"`
+		Banner   image.Image `form:"banner"`
+	}
+
+	var b body
+
+	thumb := image.NewGray16(image.Rect(0, 0, 8, 8))
+
+	d := goform.Decoder{
+		ImageHook: func(key string, img image.Image) (image.Image, error) {
+			if key == "banner" {
+				return nil, errors.New("banner not allowed")
+			}
+
+			return thumb, nil
+		},
+		CollectErrors: true,
+	}
+
+	err = d.Unmarshal(r, &b)
+	assert.EqualError(t, err, "banner not allowed")
+
+	assert.Equal(t, body{Headshot: thumb}, b)
+}
+
+func TestDecoder_ImageHookNilImage(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	var imgBuf bytes.Buffer
+	png.Encode(&imgBuf, image.NewGray16(image.Rect(0, 0, 32, 32))) // nolint
+
+	writeFormFile(w, "headshot", bytes.NewReader(imgBuf.Bytes()))
+	writeFormFile(w, "banner", bytes.NewReader(imgBuf.Bytes()))
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Headshot image.Image   `form:"headshot"`
+		Banner   *image.Gray16 `form:"banner"`
+	}
+
+	var b body
+
+	d := goform.Decoder{
+		ImageHook: func(key string, img image.Image) (image.Image, error) {
+			if key == "banner" {
+				return (*image.Gray16)(nil), nil
+			}
+
+			return nil, nil
+		},
+		CollectErrors: true,
+	}
+
+	err = d.Unmarshal(r, &b)
+	require.Error(t, err)
+
+	var multiErr goform.MultiError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr, 2)
+	assert.Equal(t, "headshot", multiErr[0].Field)
+	assert.EqualError(t, multiErr[0], "goform: ImageHook returned no image")
+	assert.Equal(t, "banner", multiErr[1].Field)
+	assert.EqualError(t, multiErr[1], "goform: ImageHook returned no image")
+
+	assert.Equal(t, body{}, b)
+}
+
+func TestUnmarshal_MultiPartFormFSFile(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormFile(w, "doc", strings.NewReader("ABCD"))
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Doc fs.File `form:"doc"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+	require.NotNil(t, b.Doc)
+
+	defer b.Doc.Close()
+
+	info, err := b.Doc.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, "doc", info.Name())
+	assert.Equal(t, int64(4), info.Size())
+
+	data, err := io.ReadAll(b.Doc)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ABCD"), data)
+}
+
+func TestDecoder_UseJSONTagForForm(t *testing.T) {
+	data := url.Values{}
+	data.Set("user_name", "rick")
+	data.Set("age", "39")
+	data.Set("Secret", "shh")
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		ID     int    `form:"id" json:"user_id"`
+		Name   string `json:"user_name,omitempty"`
+		Age    int    `json:"age"`
+		Secret string `json:"-"`
+	}
+
+	var b body
+
+	d := goform.Decoder{UseJSONTagForForm: true}
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+		Age:  39,
+	}, b)
+}
+
+func TestUnmarshal_Email(t *testing.T) {
+	type body struct {
+		Email string `form:"email,email"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"email": {"rick+test@example.com"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{Email: "rick+test@example.com"}, b)
+}
+
+func TestUnmarshal_EmailInvalid(t *testing.T) {
+	type body struct {
+		Email string `form:"email,email"`
+	}
+
+	for _, value := range []string{"rick", "rick@", "Rick <rick@example.com>"} {
+		var b body
+
+		err := goform.UnmarshalValues(url.Values{"email": {value}}, &b)
+		assert.EqualError(t, err, fmt.Sprintf("goform: invalid email address %q", value))
+	}
+}
+
+func TestUnmarshal_QueryStringAndJSONSliceOverride(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?tag=c&tag=d", strings.NewReader(`{"tags": ["a", "b"], "ids": [1, 2]}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		Tags []string `json:"tags" form:"tag"`
+		IDs  []int    `json:"ids" form:"id"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Tags: []string{"c", "d"},
+		IDs:  []int{1, 2},
+	}, b)
+}
+
+func TestDecoder_MergeSlices(t *testing.T) {
+	tests := []struct {
+		name              string
+		jsonOverridesForm bool
+		tags              []string
+	}{
+		{name: "query last", tags: []string{"a", "b", "c", "d"}},
+		{name: "json last", jsonOverridesForm: true, tags: []string{"c", "d", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodPost, "http://test/page?tag=c&tag=d", strings.NewReader(`{"tags": ["a", "b"], "ids": [1, 2]}`))
+			require.NoError(t, err)
+			require.NotNil(t, r)
+
+			r.Header.Add("Content-Type", "application/json")
+
+			type body struct {
+				Tags []string `json:"tags" form:"tag"`
+				IDs  []int    `json:"ids" form:"id"`
+			}
+
+			var b body
+
+			d := goform.Decoder{MergeSlices: true, JSONOverridesForm: tt.jsonOverridesForm}
+
+			err = d.Unmarshal(r, &b)
+			require.NoError(t, err)
+
+			assert.Equal(t, body{
+				Tags: tt.tags,
+				IDs:  []int{1, 2},
+			}, b)
+		})
+	}
+}
+
+func TestUnmarshal_NestedStruct(t *testing.T) {
+	type address struct {
+		Street string `form:"street"`
+		Zip    int    `form:"zip"`
+	}
+
+	type body struct {
+		Name     string   `form:"name"`
+		Address  address  `form:"address"`
+		Billing  *address `form:"billing"`
+		Shipping *address `form:"shipping"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{
+		"name":           {"rick"},
+		"address.street": {"1 Main St"},
+		"address.zip":    {"12345"},
+		"billing.zip":    {"54321"},
+	}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Name:    "rick",
+		Address: address{Street: "1 Main St", Zip: 12345},
+		Billing: &address{Zip: 54321},
+	}, b)
+}
+
+func TestUnmarshal_NestedStructErrors(t *testing.T) {
+	type address struct {
+		Street string `form:"street,required"`
+		Zip    int    `form:"zip"`
+	}
+
+	type body struct {
+		Address address `form:"address"`
+		Age     int     `form:"age"`
+	}
+
+	var b body
+
+	d := goform.Decoder{CollectErrors: true}
+
+	err := d.UnmarshalValues(url.Values{"address.zip": {"abc"}, "age": {"old"}}, &b)
+	require.Error(t, err)
+
+	var multiErr goform.MultiError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr, 3)
+	assert.Equal(t, "address.street", multiErr[0].Field)
+	assert.Equal(t, goform.ErrRequired, multiErr[0].Code)
+	assert.Equal(t, "address.zip", multiErr[1].Field)
+	assert.Equal(t, "age", multiErr[2].Field)
+}
+
+type node struct {
+	Value int   `form:"value"`
+	Next  *node `form:"next"`
+}
+
+func TestDecoder_MaxDepth(t *testing.T) {
+	type body struct {
+		Head node `form:"head"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"head.next.next.value": {"3"}}, &b)
+	require.NoError(t, err)
+
+	require.NotNil(t, b.Head.Next)
+	require.NotNil(t, b.Head.Next.Next)
+	assert.Equal(t, 3, b.Head.Next.Next.Value)
+	assert.Nil(t, b.Head.Next.Next.Next)
+
+	d := goform.Decoder{MaxDepth: 2}
+
+	err = d.UnmarshalValues(url.Values{"head.next.next.value": {"3"}}, &b)
+	assert.EqualError(t, err, "goform: field [head.next.next] is nested more than 2 levels deep")
+}
+
+func TestUnmarshal_Flag(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "http://test/page?verbose&debug=false&force=1", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	type body struct {
+		Verbose bool `form:"verbose,flag"`
+		Debug   bool `form:"debug,flag"`
+		Force   bool `form:"force,flag"`
+		DryRun  bool `form:"dry_run,flag"`
+	}
+
+	b := body{Debug: true, DryRun: true}
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Verbose: true,
+		Force:   true,
+	}, b)
+}
+
+func TestUnmarshal_FlagNoValues(t *testing.T) {
+	type body struct {
+		Verbose bool `form:"verbose,flag"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"verbose": {}}, &b)
+	require.NoError(t, err)
+
+	assert.True(t, b.Verbose)
+}
+
+func TestUnmarshal_URLDecode(t *testing.T) {
+	type body struct {
+		Redirect string   `form:"redirect,urldecode"`
+		Tags     []string `form:"tag,urldecode"`
+		Raw      string   `form:"raw"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{
+		"redirect": {"%2Fhome%3Ftab%3D1"},
+		"tag":      {"a%20b", "c+d"},
+		"raw":      {"%2Fhome"},
+	}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Redirect: "/home?tab=1",
+		Tags:     []string{"a b", "c d"},
+		Raw:      "%2Fhome",
+	}, b)
+}
+
+func TestUnmarshal_URLDecodeInvalid(t *testing.T) {
+	type body struct {
+		Redirect string `form:"redirect,urldecode"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"redirect": {"%zz"}}, &b)
+	assert.EqualError(t, err, `invalid URL escape "%zz"`)
+}
+
+func TestUnmarshal_MultiPartFormByteSlicesBase64(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormFile(w, "docs", strings.NewReader(base64.StdEncoding.EncodeToString([]byte("ABCD"))))
+	writeFormFile(w, "docs", strings.NewReader(base64.StdEncoding.EncodeToString([]byte("EFGH"))))
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Docs [][]byte `form:"docs,base64"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Docs: [][]byte{[]byte("ABCD"), []byte("EFGH")},
+	}, b)
+}
+
+func TestBind(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `json:"name"`
+	}
+
+	b, err := goform.Bind[body](r)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+	}, b)
+}
+
+func TestUnmarshal_BracketMap(t *testing.T) {
+	type body struct {
+		Scores map[int]string    `form:"scores"`
+		Meta   map[string]string `form:"meta"`
+		Flags  map[string]bool   `form:"flags"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{
+		"scores[1]":  {"a"},
+		"scores[20]": {"b"},
+		"meta[env]":  {"prod"},
+		"flags[x]":   {"yes"},
+	}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Scores: map[int]string{1: "a", 20: "b"},
+		Meta:   map[string]string{"env": "prod"},
+		Flags:  map[string]bool{"x": true},
+	}, b)
+}
+
+func TestUnmarshal_BracketMapInvalidKey(t *testing.T) {
+	type body struct {
+		Scores map[int]string `form:"scores"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"scores[one]": {"a"}}, &b)
+	assert.EqualError(t, err, `goform: field [scores] has invalid key "one": strconv.ParseInt: parsing "one": invalid syntax`)
+}
+
+func TestUnmarshal_BracketMapMultipartFile(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormField(w, "scores[2]", "b")
+	writeFormFile(w, "scores[1]", strings.NewReader("ABCD"))
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Scores map[int]string `form:"scores"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Scores: map[int]string{2: "b"},
+	}, b)
+}
+
+func TestUnmarshal_TimeSliceCSV(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	type body struct {
+		Dates []time.Time `form:"dates,csv" format:"2006-01-02" tz:"America/Chicago"`
+	}
+
+	var b body
+
+	err = goform.UnmarshalValues(url.Values{"dates": {"2020-01-02,2020-01-03"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Dates: []time.Time{
+			time.Date(2020, 1, 2, 0, 0, 0, 0, chicago),
+			time.Date(2020, 1, 3, 0, 0, 0, 0, chicago),
+		},
+	}, b)
+
+	err = goform.UnmarshalValues(url.Values{"dates": {"2020-01-02,2020-13-01"}}, &b)
+	require.Error(t, err)
+
+	var fieldErr *goform.FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "dates", fieldErr.Field)
+	assert.EqualError(t, err, `parsing time "2020-13-01": month out of range (field [dates], element 1)`)
+}
+
+func TestDecoder_DisallowBodyForEmptyStruct(t *testing.T) {
+	type body struct{}
+
+	d := goform.Decoder{DisallowBodyForEmptyStruct: true}
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	err = d.Unmarshal(r, &body{})
+	assert.EqualError(t, err, "goform: request has a body, but v has no fields to bind it to")
+
+	r = newChunkedRequest(t, "http://test/page", `{"name": "rick"}`)
+	r.Header.Add("Content-Type", "application/json")
+
+	err = d.Unmarshal(r, &body{})
+	assert.EqualError(t, err, "goform: request has a body, but v has no fields to bind it to")
+
+	r, err = http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	err = d.Unmarshal(r, &body{})
+	assert.NoError(t, err)
+
+	r, err = http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json")
+
+	err = goform.Unmarshal(r, &body{})
+	assert.NoError(t, err)
+}
+
+func TestUnmarshal_MultiPartFormRaw(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormField(w, "name", "rick")
+	writeFormFile(w, "data", strings.NewReader("ABCD"))
+
+	w.Close() // nolint
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	type body struct {
+		Name string          `form:"name"`
+		Form *multipart.Form `form:"*"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, "rick", b.Name)
+	require.NotNil(t, b.Form)
+	assert.Equal(t, []string{"rick"}, b.Form.Value["name"])
+	require.Len(t, b.Form.File["data"], 1)
+	assert.Equal(t, int64(4), b.Form.File["data"][0].Size)
+}
+
+func TestUnmarshal_MultiPartFormRawNotMultipart(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?name=rick", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	type body struct {
+		Name string          `form:"name"`
+		Form *multipart.Form `form:"*"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{Name: "rick"}, b)
+}
+
+func TestDecoder_NameFunc(t *testing.T) {
+	type body struct {
+		ID        int `form:"id"`
+		FirstName string
+		LastName  string
+		Internal  string
+	}
+
+	d := goform.Decoder{
+		NameFunc: func(fieldName string) string {
+			if fieldName == "Internal" {
+				return "-"
+			}
+
+			return strings.ToLower(fieldName[:1]) + fieldName[1:]
+		},
+	}
+
+	var b body
+
+	err := d.UnmarshalValues(url.Values{"id": {"1"}, "firstName": {"rick"}, "lastName": {"bassham"}, "Internal": {"x"}, "-": {"y"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:        1,
+		FirstName: "rick",
+		LastName:  "bassham",
+	}, b)
+}
+
+type status uint8
+
+const (
+	statusInactive status = iota
+	statusActive
+)
+
+func TestDecoder_RegisterEnum(t *testing.T) {
+	type body struct {
+		Status  status   `form:"status"`
+		Other   status   `form:"other"`
+		History []status `form:"history"`
+	}
+
+	d := goform.Decoder{}
+	d.RegisterEnum(status(0), map[string]int64{
+		"inactive": int64(statusInactive),
+		"active":   int64(statusActive),
+	})
+
+	var b body
+
+	err := d.UnmarshalValues(url.Values{"status": {"active"}, "other": {"0"}, "history": {"inactive", "active"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Status:  statusActive,
+		Other:   statusInactive,
+		History: []status{statusInactive, statusActive},
+	}, b)
+
+	err = d.UnmarshalValues(url.Values{"status": {"deleted"}}, &b)
+	require.Error(t, err)
+
+	var fieldErr *goform.FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "status", fieldErr.Field)
+	assert.EqualError(t, err, `goform: invalid status "deleted"`)
+}
+
+func TestDecoder_RegisterEnumOverflow(t *testing.T) {
+	type level int8
+
+	type body struct {
+		Status status `form:"status"`
+		Level  level  `form:"level"`
+	}
+
+	d := goform.Decoder{}
+	d.RegisterEnum(status(0), nil)
+	d.RegisterEnum(level(0), nil)
+
+	var b body
+
+	err := d.UnmarshalValues(url.Values{"status": {"255"}, "level": {"-128"}}, &b)
+	require.NoError(t, err)
+	assert.Equal(t, body{Status: 255, Level: -128}, b)
+
+	err = d.UnmarshalValues(url.Values{"status": {"256"}}, &b)
+	assert.EqualError(t, err, `goform: invalid status "256"`)
+
+	err = d.UnmarshalValues(url.Values{"status": {"-1"}}, &b)
+	assert.EqualError(t, err, `goform: invalid status "-1"`)
+
+	err = d.UnmarshalValues(url.Values{"level": {"300"}}, &b)
+	assert.EqualError(t, err, `goform: invalid level "300"`)
+}
+
+func TestDecoder_RegisterEnumInvalid(t *testing.T) {
+	d := goform.Decoder{}
+
+	assert.PanicsWithValue(t, "goform: RegisterEnum expects an integer type", func() {
+		d.RegisterEnum("active", map[string]int64{"active": 1})
+	})
+
+	assert.PanicsWithValue(t, "goform: RegisterEnum value deleted overflows status", func() {
+		d.RegisterEnum(status(0), map[string]int64{"deleted": -1})
+	})
+
+	assert.PanicsWithValue(t, "goform: RegisterEnum value huge overflows status", func() {
+		d.RegisterEnum(status(0), map[string]int64{"huge": 256})
+	})
+}
+
+func TestUnmarshal_PrePopulated(t *testing.T) {
+	type address struct {
+		Street string `form:"street"`
+		Zip    int    `form:"zip"`
+	}
+
+	type body struct {
+		ID      int               `form:"id"`
+		Name    string            `form:"name"`
+		Age     *int              `form:"age"`
+		Nick    *string           `form:"nick"`
+		Tags    []string          `form:"tag"`
+		Meta    map[string]string `form:"meta"`
+		Address *address          `form:"address"`
+		Created time.Time         `form:"created"`
+	}
+
+	age := 39
+	nick := "ricky"
+	created := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	b := body{
+		ID:      1,
+		Name:    "rick",
+		Age:     &age,
+		Nick:    &nick,
+		Tags:    []string{"a"},
+		Meta:    map[string]string{"env": "prod"},
+		Address: &address{Street: "1 Main St", Zip: 12345},
+		Created: created,
+	}
+
+	err := goform.UnmarshalValues(url.Values{"name": {"bob"}, "address.zip": {"54321"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, b.ID)
+	assert.Equal(t, "bob", b.Name)
+	assert.Same(t, &age, b.Age)
+	assert.Equal(t, 39, *b.Age)
+	assert.Same(t, &nick, b.Nick)
+	assert.Equal(t, "ricky", *b.Nick)
+	assert.Equal(t, []string{"a"}, b.Tags)
+	assert.Equal(t, map[string]string{"env": "prod"}, b.Meta)
+	assert.Equal(t, &address{Street: "1 Main St", Zip: 54321}, b.Address)
+	assert.Equal(t, created, b.Created)
+}
+
+func TestUnmarshal_BracketGrammar(t *testing.T) {
+	type address struct {
+		City string `form:"city"`
+		Zip  int    `form:"zip"`
+	}
+
+	type user struct {
+		Name    string  `form:"name"`
+		Age     int     `form:"age"`
+		Address address `form:"address"`
+	}
+
+	type item struct {
+		SKU string `form:"sku"`
+		Qty int    `form:"qty"`
+	}
+
+	type body struct {
+		User   user            `form:"user"`
+		Users  map[string]user `form:"users"`
+		Items  []item          `form:"items"`
+		Tags   []string        `form:"tags"`
+		Labels []string        `form:"labels[]"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{
+		"user[name]":            {"rick"},
+		"user[address][city]":   {"NYC"},
+		"user.address.zip":      {"10001"},
+		"users[bob][age]":       {"40"},
+		"users[bob][name]":      {"bob"},
+		"users[jim][age]":       {"41"},
+		"items[10][sku]":        {"b"},
+		"items[2][sku]":         {"a"},
+		"items[2][qty]":         {"3"},
+		"tags[]":                {"x", "y"},
+		"labels[]":              {"z"},
+		"user[address][ignore]": {"?"},
+	}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		User: user{Name: "rick", Address: address{City: "NYC", Zip: 10001}},
+		Users: map[string]user{
+			"bob": {Name: "bob", Age: 40},
+			"jim": {Age: 41},
+		},
+		Items:  []item{{SKU: "a", Qty: 3}, {SKU: "b"}},
+		Tags:   []string{"x", "y"},
+		Labels: []string{"z"},
+	}, b)
+}
+
+func TestUnmarshal_BracketGrammarMixedKeys(t *testing.T) {
+	type body struct {
+		Tags []string `form:"tags"`
+	}
+
+	for i := 0; i < 10; i++ {
+		var b body
+
+		err := goform.UnmarshalValues(url.Values{"tags": {"a"}, "tags[]": {"b", "c"}}, &b)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"a", "b", "c"}, b.Tags)
+	}
+}
+
+func TestUnmarshal_BracketGrammarInvalidIndex(t *testing.T) {
+	type item struct {
+		SKU string `form:"sku"`
+	}
+
+	type body struct {
+		Items []item `form:"items"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"items[first][sku]": {"a"}}, &b)
+	assert.EqualError(t, err, `goform: field [items] has invalid index "first"`)
+}
+
+func TestUnmarshal_OneOf(t *testing.T) {
+	type body struct {
+		Enabled int      `form:"enabled" oneof:"0 1"`
+		Color   string   `form:"color" oneof:"red green blue"`
+		Sizes   []string `form:"size" oneof:"s m l"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"enabled": {"01"}, "color": {"green"}, "size": {"s", "l"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Enabled: 1,
+		Color:   "green",
+		Sizes:   []string{"s", "l"},
+	}, b)
+
+	d := goform.Decoder{CollectErrors: true}
+
+	err = d.UnmarshalValues(url.Values{"enabled": {"2"}, "color": {"pink"}, "size": {"s", "xl"}}, &b)
+	require.Error(t, err)
+
+	var multiErr goform.MultiError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr, 3)
+	assert.Equal(t, goform.ErrOutOfRange, multiErr[0].Code)
+	assert.EqualError(t, multiErr[0], "goform: value must be one of 0 1")
+	assert.EqualError(t, multiErr[1], "goform: value must be one of red green blue")
+	assert.EqualError(t, multiErr[2], "goform: value must be one of s m l (field [size], element 1)")
+	assert.Equal(t, goform.ErrOutOfRange, multiErr[2].Code)
+}
+
+func TestUnmarshal_ItemCount(t *testing.T) {
+	type body struct {
+		Tags []string `form:"tags" minitems:"2" maxitems:"3"`
+		IDs  []int    `form:"ids" maxitems:"1"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"tags": {"a", "b", "c"}, "ids": {"1"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Tags: []string{"a", "b", "c"},
+		IDs:  []int{1},
+	}, b)
+
+	d := goform.Decoder{CollectErrors: true}
+
+	err = d.UnmarshalValues(url.Values{"tags": {"a"}, "ids": {"1", "2"}}, &body{})
+	require.Error(t, err)
+
+	var multiErr goform.MultiError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr, 2)
+	assert.Equal(t, goform.ErrOutOfRange, multiErr[0].Code)
+	assert.EqualError(t, multiErr[0], "goform: field [tags] has 1 items, fewer than minitems of 2")
+	assert.EqualError(t, multiErr[1], "goform: field [ids] has 2 items, more than maxitems of 1")
+
+	// absent fields are left to the required option
+	err = goform.UnmarshalValues(url.Values{}, &body{})
+	require.NoError(t, err)
+}
+
+func TestUnmarshal_UTF8(t *testing.T) {
+	type body struct {
+		Note  string `form:"note,utf8"`
+		Title string `form:"title,sanitizeutf8"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"note": {"héllo"}, "title": {"a\xffb"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Note:  "héllo",
+		Title: "a\uFFFDb",
+	}, b)
+
+	err = goform.UnmarshalValues(url.Values{"note": {"a\xffb"}}, &b)
+	assert.EqualError(t, err, `goform: invalid utf-8 in "a\xffb"`)
+}
+
+func TestUnmarshal_Scanner(t *testing.T) {
+	type body struct {
+		Name  sql.NullString `form:"name"`
+		Count sql.NullInt64  `form:"count"`
+		Note  sql.NullString `form:"note"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"name": {"rick"}, "count": {"42"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Name:  sql.NullString{String: "rick", Valid: true},
+		Count: sql.NullInt64{Int64: 42, Valid: true},
+	}, b)
+
+	err = goform.UnmarshalValues(url.Values{"count": {"many"}}, &b)
+	require.Error(t, err)
+}
+
+func TestUnmarshal_TrimAndNormalizeNewlines(t *testing.T) {
+	type body struct {
+		Name string `form:"name,trim"`
+		Bio  string `form:"bio,normalizenewlines"`
+		Note string `form:"note,trim,normalizenewlines"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{
+		"name": {"  rick \t"},
+		"bio":  {"line one\r\nline two\rline three\r\n"},
+		"note": {"\r\n hello\r\nworld \r\n"},
+	}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Name: "rick",
+		Bio:  "line one\nline two\nline three\n",
+		Note: "hello\nworld",
+	}, b)
+}
+
+func TestUnmarshal_RawQuery(t *testing.T) {
+	data := url.Values{}
+	data.Set("name", "rick")
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1&filter[status]=open&rawquery=x", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		ID    int        `form:"id"`
+		Name  string     `form:"name"`
+		Query url.Values `form:",rawquery"`
+	}
+
+	var b body
+
+	err = goform.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:    1,
+		Name:  "rick",
+		Query: url.Values{"id": {"1"}, "filter[status]": {"open"}, "rawquery": {"x"}},
+	}, b)
+
+	type badBody struct {
+		Query string `form:",rawquery"`
+	}
+
+	err = goform.UnmarshalValues(url.Values{}, &badBody{})
+	assert.EqualError(t, err, "goform: rawquery option requires a url.Values field")
+}
+
+func TestUnmarshal_TimeOfDay(t *testing.T) {
+	type body struct {
+		Opens  time.Time      `form:"opens" format:"15:04"`
+		Closes time.Duration  `form:"closes" format:"15:04"`
+		Alarm  *time.Duration `form:"alarm" format:"15:04:05"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{"opens": {"09:15"}, "closes": {"14:30"}, "alarm": {"06:00:30"}}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(0, time.January, 1, 9, 15, 0, 0, time.UTC), b.Opens)
+	assert.Equal(t, 14*time.Hour+30*time.Minute, b.Closes)
+	require.NotNil(t, b.Alarm)
+	assert.Equal(t, 6*time.Hour+30*time.Second, *b.Alarm)
+
+	err = goform.UnmarshalValues(url.Values{"closes": {"2:30pm"}}, &b)
+	require.Error(t, err)
+}
+
+func TestDecoder_AllowedContentTypes(t *testing.T) {
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name" json:"name"`
+	}
+
+	d := goform.Decoder{AllowedContentTypes: []string{"multipart/form-data"}}
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page?id=1", strings.NewReader(`{"name": "rick"}`))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/json; charset=utf-8")
+
+	var b body
+
+	err = d.Unmarshal(r, &b)
+	assert.EqualError(t, err, "goform: unsupported content type application/json")
+	assert.Equal(t, body{}, b)
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormField(w, "name", "rick")
+
+	w.Close() // nolint
+
+	r, err = http.NewRequest(http.MethodPost, "http://test/page?id=1", &buf)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", w.FormDataContentType())
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+	}, b)
+
+	// without a Content-Type only the query string is bound
+	r, err = http.NewRequest(http.MethodGet, "http://test/page?id=2", strings.NewReader(""))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	err = d.Unmarshal(r, &b)
+	require.NoError(t, err)
+	assert.Equal(t, 2, b.ID)
+}
+
+func TestUnmarshalPrefixed(t *testing.T) {
+	data := url.Values{}
+	data.Set("shipping_city", "Austin")
+	data.Set("shipping_zip", "78701")
+	data.Set("billing_city", "Dallas")
+	data.Set("billing_zip", "75201")
+	data.Set("billing_geo.lat", "32.7")
+
+	r, err := http.NewRequest(http.MethodPost, "http://test/page", strings.NewReader(data.Encode()))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type geo struct {
+		Lat float64 `form:"lat"`
+	}
+
+	type address struct {
+		City string `form:"city"`
+		Zip  string `form:"zip"`
+		Geo  *geo   `form:"geo"`
+	}
+
+	var shipping, billing address
+
+	err = goform.UnmarshalPrefixed(r, &shipping, "shipping_")
+	require.NoError(t, err)
+
+	err = goform.UnmarshalPrefixed(r, &billing, "billing_")
+	require.NoError(t, err)
+
+	assert.Equal(t, address{City: "Austin", Zip: "78701"}, shipping)
+	assert.Equal(t, address{City: "Dallas", Zip: "75201", Geo: &geo{Lat: 32.7}}, billing)
+}
+
+func TestUnmarshal_Endian(t *testing.T) {
+	type body struct {
+		Big    uint32 `form:"big" endian:"big"`
+		Little uint32 `form:"little" endian:"little"`
+		Signed int16  `form:"signed" endian:"big"`
+		Base64 int64  `form:"b64,base64" endian:"little"`
+	}
+
+	var b body
+
+	err := goform.UnmarshalValues(url.Values{
+		"big":    {"01020304"},
+		"little": {"01020304"},
+		"signed": {"fffe"},
+		"b64":    {"AQAAAAAAAAA="},
+	}, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		Big:    0x01020304,
+		Little: 0x04030201,
+		Signed: -2,
+		Base64: 1,
+	}, b)
+
+	err = goform.UnmarshalValues(url.Values{"big": {"010203"}}, &b)
+	assert.EqualError(t, err, "goform: value has 3 bytes, expected 4")
+
+	type badBody struct {
+		Value string `form:"value" endian:"big"`
+	}
+
+	err = goform.UnmarshalValues(url.Values{"value": {"01"}}, &badBody{})
+	assert.EqualError(t, err, "goform: endian tag requires an integer field")
+}
+
+func TestDecoder_BufferBody(t *testing.T) {
+	data := url.Values{}
+	data.Set("name", "rick")
+
+	r := newChunkedRequest(t, "http://test/page?id=1", data.Encode())
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	type body struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+	}
+
+	var b body
+
+	d := goform.Decoder{BufferBody: true}
+
+	err := d.Unmarshal(r, &b)
+	require.NoError(t, err)
+
+	assert.Equal(t, body{
+		ID:   1,
+		Name: "rick",
+	}, b)
+
+	raw, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "name=rick", string(raw))
+}
+
+func TestDecoder_BufferBodyLimits(t *testing.T) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	writeFormFile(w, "file1", bytes.NewReader(make([]byte, 3<<20)))
+
+	w.Close() // nolint
+
+	type body struct {
+		File []byte `form:"file1"`
+	}
+
+	tests := []struct {
+		name    string
+		decoder goform.Decoder
+		err     string
+	}{
+		{
+			name:    "content length",
+			decoder: goform.Decoder{BufferBody: true, MaxTotalUploadBytes: 10, MaxContentLength: 100},
+			err:     "goform: content length exceeds 100 bytes",
+		},
+		{
+			name:    "upload size",
+			decoder: goform.Decoder{BufferBody: true, MaxTotalUploadBytes: 10},
+			err:     "goform: total upload size exceeds 10 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newChunkedRequest(t, "http://test/page", buf.String())
+			r.Header.Add("Content-Type", w.FormDataContentType())
+
+			var b body
+
+			err := tt.decoder.Unmarshal(r, &b)
+			assert.EqualError(t, err, tt.err)
+			assert.Nil(t, b.File)
+		})
+	}
+}